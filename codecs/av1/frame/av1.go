@@ -4,7 +4,12 @@
 // Package frame provides code to construct complete media frames from packetized media.
 package frame
 
-import "github.com/pion/rtp/codecs"
+import (
+	"fmt"
+
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/rtp/codecs/av1/obu"
+)
 
 // AV1 represents a collection of OBUs given a stream of AV1 Packets.
 // Each AV1 RTP Packet is a collection of OBU Elements. Each OBU Element may be a full OBU, or just a fragment of one.
@@ -14,6 +19,18 @@ type AV1 struct {
 	// Buffer for fragmented OBU. If ReadFrames is called on a RTP Packet
 	// that doesn't contain a fully formed OBU
 	obuBuffer []byte
+
+	// MaxPartialPackets limits the number of packets a fragmented OBU can span.
+	// When exceeded, the fragment is dropped and ReadFrames returns an error.
+	// Zero means no limit.
+	MaxPartialPackets int
+
+	// KeepPaddingOBUs makes ReadFrames return OBU_PADDING OBUs, which are
+	// dropped by default.
+	KeepPaddingOBUs bool
+
+	// number of packets the fragment in obuBuffer spans
+	partialPackets int
 }
 
 func (f *AV1) pushOBUElement(isFirstOBUFragment *bool, obuElement []byte, obuList [][]byte) [][]byte {
@@ -30,6 +47,13 @@ func (f *AV1) pushOBUElement(isFirstOBUFragment *bool, obuElement []byte, obuLis
 	return append(obuList, obuElement)
 }
 
+// Reset discards any cached OBU fragment. It should be called
+// when the SSRC of the depacketized stream changes.
+func (f *AV1) Reset() {
+	f.obuBuffer = nil
+	f.partialPackets = 0
+}
+
 // ReadFrames processes the codecs.AV1Packet and returns fully constructed frames.
 func (f *AV1) ReadFrames(pkt *codecs.AV1Packet) ([][]byte, error) {
 	OBUs := [][]byte{}
@@ -40,10 +64,42 @@ func (f *AV1) ReadFrames(pkt *codecs.AV1Packet) ([][]byte, error) {
 	}
 
 	if pkt.Y && len(OBUs) > 0 {
+		// The cached fragment continues the previous one if it is the only OBU element
+		if pkt.Z && len(pkt.OBUElements) == 1 {
+			f.partialPackets++
+		} else {
+			f.partialPackets = 1
+		}
+
 		// Take copy of OBUElement that is being cached
 		f.obuBuffer = append(f.obuBuffer, append([]byte{}, OBUs[len(OBUs)-1]...)...)
 		OBUs = OBUs[:len(OBUs)-1]
+
+		if f.MaxPartialPackets > 0 && f.partialPackets > f.MaxPartialPackets {
+			f.Reset()
+
+			return nil, fmt.Errorf("%w: more than %d packets", codecs.ErrPartialFrameTooLong, f.MaxPartialPackets)
+		}
+	} else {
+		f.partialPackets = 0
+	}
+
+	if !f.KeepPaddingOBUs {
+		OBUs = dropPaddingOBUs(OBUs)
 	}
 
 	return OBUs, nil
 }
+
+// dropPaddingOBUs removes the OBU_PADDING OBUs from OBUs, in place.
+func dropPaddingOBUs(OBUs [][]byte) [][]byte {
+	kept := OBUs[:0]
+	for _, o := range OBUs {
+		if header, err := obu.ParseOBUHeader(o); err == nil && header.Type == obu.TypePadding {
+			continue
+		}
+		kept = append(kept, o)
+	}
+
+	return kept
+}
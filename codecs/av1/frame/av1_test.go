@@ -4,6 +4,7 @@
 package frame
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -47,6 +48,104 @@ func TestAV1_ReadFrames(t *testing.T) {
 	}
 }
 
+func TestAV1_ReadFrames_Padding(t *testing.T) {
+	padding := []byte{0x78, 0x00, 0x00}
+	frameOBU := []byte{0x30, 0x01}
+
+	frames, err := (&AV1{}).ReadFrames(&codecs.AV1Packet{OBUElements: [][]byte{padding, frameOBU}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{frameOBU}) {
+		t.Fatalf("Padding OBU should be dropped, %v", frames)
+	}
+
+	// A fragmented padding OBU is dropped once reassembled
+	fragm := &AV1{}
+	if _, err = fragm.ReadFrames(&codecs.AV1Packet{Y: true, OBUElements: [][]byte{frameOBU, padding[:1]}}); err != nil {
+		t.Fatal(err)
+	}
+	frames, err = fragm.ReadFrames(&codecs.AV1Packet{Z: true, OBUElements: [][]byte{padding[1:]}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{}) {
+		t.Fatalf("Reassembled padding OBU should be dropped, %v", frames)
+	}
+
+	frames, err = (&AV1{KeepPaddingOBUs: true}).ReadFrames(&codecs.AV1Packet{OBUElements: [][]byte{padding, frameOBU}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{padding, frameOBU}) {
+		t.Fatalf("Padding OBU should be kept, %v", frames)
+	}
+}
+
+func TestAV1_Reset(t *testing.T) {
+	fragm := &AV1{}
+	frames, err := fragm.ReadFrames(&codecs.AV1Packet{Y: true, OBUElements: [][]byte{{0x00}}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{}) {
+		t.Fatalf("No frames should be generated, %v", frames)
+	}
+
+	fragm.Reset()
+
+	frames, err = fragm.ReadFrames(&codecs.AV1Packet{Z: true, OBUElements: [][]byte{{0x01}}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{}) {
+		t.Fatalf("Reset should discard the cached fragment, %v", frames)
+	}
+}
+
+func TestAV1_MaxPartialPackets(t *testing.T) {
+	fragm := &AV1{MaxPartialPackets: 3}
+
+	// An OBU spanning exactly the limit is reassembled
+	packets := []*codecs.AV1Packet{
+		{Y: true, OBUElements: [][]byte{{0x01}}},
+		{Z: true, Y: true, OBUElements: [][]byte{{0x02}}},
+		{Z: true, Y: true, OBUElements: [][]byte{{0x03}}},
+		{Z: true, OBUElements: [][]byte{{0x04}}},
+	}
+	var frames [][]byte
+	for _, pkt := range packets {
+		out, err := fragm.ReadFrames(pkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, out...)
+	}
+	if !reflect.DeepEqual(frames, [][]byte{{0x01, 0x02, 0x03, 0x04}}) {
+		t.Fatalf("Unexpected frames %v", frames)
+	}
+
+	// Fragments without an end are dropped once over the limit
+	if _, err := fragm.ReadFrames(&codecs.AV1Packet{Y: true, OBUElements: [][]byte{{0x05}}}); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		_, err = fragm.ReadFrames(&codecs.AV1Packet{Z: true, Y: true, OBUElements: [][]byte{{0x06}}})
+		if i < 2 && err != nil {
+			t.Fatalf("Unexpected error at fragment %d: %v", i, err)
+		}
+	}
+	if !errors.Is(err, codecs.ErrPartialFrameTooLong) {
+		t.Fatalf("Expected %v, got %v", codecs.ErrPartialFrameTooLong, err)
+	}
+	if fragm.obuBuffer != nil {
+		t.Fatal("Partial OBU should be dropped")
+	}
+
+	frames, err = fragm.ReadFrames(&codecs.AV1Packet{Z: true, OBUElements: [][]byte{{0x07}}})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(frames, [][]byte{}) {
+		t.Fatalf("Dropped fragment should not be completed, %v", frames)
+	}
+}
+
 // Marshal some AV1 Frames to RTP, assert that AV1 can get them back in the original format.
 func TestAV1_ReadFrames_E2E(t *testing.T) {
 	const mtu = 1500
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package obu
+
+// Metadata types, as defined in the AV1 specification section 6.7.1.
+const (
+	MetadataTypeHDRCLL      uint = 1
+	MetadataTypeHDRMDCV     uint = 2
+	MetadataTypeScalability uint = 3
+	MetadataTypeITUTT35     uint = 4
+	MetadataTypeTimecode    uint = 5
+)
+
+// ParseMetadata reads the metadata_type of the payload of an OBU_METADATA and
+// returns it along with the remaining metadata. The returned data aliases
+// payload and still contains the trailing bits of the OBU.
+func ParseMetadata(payload []byte) (metadataType uint, data []byte, err error) {
+	metadataType, n, err := ReadLeb128(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return metadataType, payload[n:], nil
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package obu
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	for name, test := range map[string]struct {
+		payload      []byte
+		metadataType uint
+		data         []byte
+	}{
+		"HDRCLL": {
+			// max_cll, max_fall, trailing bits
+			payload:      []byte{0x01, 0x03, 0xE8, 0x01, 0x90, 0x80},
+			metadataType: MetadataTypeHDRCLL,
+			data:         []byte{0x03, 0xE8, 0x01, 0x90, 0x80},
+		},
+		"HDRMDCV": {
+			payload:      append([]byte{0x02}, make([]byte, 24)...),
+			metadataType: MetadataTypeHDRMDCV,
+			data:         make([]byte, 24),
+		},
+		"ITUTT35": {
+			// country code, provider code, trailing bits
+			payload:      []byte{0x04, 0xB5, 0x00, 0x3C, 0x80},
+			metadataType: MetadataTypeITUTT35,
+			data:         []byte{0xB5, 0x00, 0x3C, 0x80},
+		},
+		"MultiByteType": {
+			payload:      []byte{0x80, 0x01, 0xAA},
+			metadataType: 128,
+			data:         []byte{0xAA},
+		},
+		"NoData": {
+			payload:      []byte{0x05},
+			metadataType: MetadataTypeTimecode,
+			data:         []byte{},
+		},
+	} {
+		metadataType, data, err := ParseMetadata(test.payload)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if metadataType != test.metadataType {
+			t.Errorf("%s: expected metadata type %d, got %d", name, test.metadataType, metadataType)
+		}
+		if !bytes.Equal(data, test.data) {
+			t.Errorf("%s: expected data %x, got %x", name, test.data, data)
+		}
+	}
+
+	for _, payload := range [][]byte{nil, {0x80}} {
+		if _, _, err := ParseMetadata(payload); !errors.Is(err, ErrFailedToReadLEB128) {
+			t.Errorf("Expected %v for %x, got %v", ErrFailedToReadLEB128, payload, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package obu
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrShortHeader indicates that a buffer ended before an OBU header could be read.
+	ErrShortHeader = errors.New("payload ended before OBU header was finished")
+	// ErrForbiddenBitSet indicates that the forbidden bit of an OBU header is set.
+	ErrForbiddenBitSet = errors.New("OBU header forbidden bit is set")
+	// ErrInvalidOBUType indicates that an OBU header carries a reserved OBU type.
+	ErrInvalidOBUType = errors.New("invalid OBU type")
+	// ErrMissingOBUSizeField indicates that an OBU lacks the obu_size field.
+	ErrMissingOBUSizeField = errors.New("OBU has no size field")
+	// ErrOBUSizeTooLarge indicates that an OBU size exceeds the remaining data.
+	ErrOBUSizeTooLarge = errors.New("OBU size exceeds the remaining data")
+)
+
+const (
+	forbiddenBitMask    = byte(0b10000000)
+	typeMask            = byte(0b01111000)
+	typeBitshift        = 3
+	extensionFlagMask   = byte(0b00000100)
+	temporalIDBitshift  = 5
+	spatialIDMask       = byte(0b00011000)
+	spatialIDBitshift   = 3
+	headerSize          = 1
+	extensionHeaderSize = 1
+)
+
+// HasSizeFieldMask is the obu_has_size_field flag in the first byte of an OBU header.
+const HasSizeFieldMask = byte(0b00000010)
+
+// Type is the type of an OBU, as defined in the AV1 specification section 6.2.2.
+type Type uint8
+
+// OBU types.
+const (
+	TypeSequenceHeader       Type = 1
+	TypeTemporalDelimiter    Type = 2
+	TypeFrameHeader          Type = 3
+	TypeTileGroup            Type = 4
+	TypeMetadata             Type = 5
+	TypeFrame                Type = 6
+	TypeRedundantFrameHeader Type = 7
+	TypeTileList             Type = 8
+	TypePadding              Type = 15
+)
+
+// IsValid reports whether t is not a reserved OBU type.
+func (t Type) IsValid() bool {
+	return (t >= TypeSequenceHeader && t <= TypeTileList) || t == TypePadding
+}
+
+// Header is an OBU header, as defined in the AV1 specification section 5.3.2.
+type Header struct {
+	Type         Type
+	HasExtension bool
+	HasSizeField bool
+	TemporalID   uint8
+	SpatialID    uint8
+}
+
+// ParseOBUHeader parses the OBU header at the start of data.
+func ParseOBUHeader(data []byte) (*Header, error) {
+	if len(data) < headerSize {
+		return nil, ErrShortHeader
+	}
+	if data[0]&forbiddenBitMask != 0 {
+		return nil, ErrForbiddenBitSet
+	}
+
+	header := &Header{
+		Type:         Type((data[0] & typeMask) >> typeBitshift),
+		HasExtension: data[0]&extensionFlagMask != 0,
+		HasSizeField: data[0]&HasSizeFieldMask != 0,
+	}
+
+	if header.HasExtension {
+		if len(data) < headerSize+extensionHeaderSize {
+			return nil, ErrShortHeader
+		}
+		header.TemporalID = data[1] >> temporalIDBitshift
+		header.SpatialID = (data[1] & spatialIDMask) >> spatialIDBitshift
+	}
+
+	return header, nil
+}
+
+// Size returns the size of the marshaled header, excluding the obu_size field.
+func (h *Header) Size() int {
+	if h.HasExtension {
+		return headerSize + extensionHeaderSize
+	}
+
+	return headerSize
+}
+
+// Validate walks the OBUs of a temporal unit in the low overhead bitstream
+// format and checks that each has a valid header and a size field
+// consistent with the data. Returned errors include the offset of the
+// offending OBU.
+func Validate(data []byte) error {
+	offset := 0
+	for offset < len(data) {
+		_, end, err := readOBU(data, offset)
+		if err != nil {
+			return err
+		}
+		offset = end
+	}
+
+	return nil
+}
+
+// SplitTemporalUnits splits a bitstream in the low overhead bitstream format
+// into temporal units, each starting with its temporal delimiter OBU. OBUs
+// preceding the first temporal delimiter form a temporal unit of their own.
+// The OBUs are checked like Validate does, and the returned temporal units
+// reference data.
+func SplitTemporalUnits(data []byte) ([][]byte, error) {
+	var temporalUnits [][]byte
+	start, offset := 0, 0
+	for offset < len(data) {
+		header, end, err := readOBU(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		if header.Type == TypeTemporalDelimiter && offset > start {
+			temporalUnits = append(temporalUnits, data[start:offset])
+			start = offset
+		}
+		offset = end
+	}
+
+	if offset > start {
+		temporalUnits = append(temporalUnits, data[start:offset])
+	}
+
+	return temporalUnits, nil
+}
+
+// readOBU checks the OBU at offset in data and returns its header and the
+// offset of the following OBU.
+func readOBU(data []byte, offset int) (*Header, int, error) {
+	header, err := ParseOBUHeader(data[offset:])
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w at offset %d", err, offset)
+	}
+	if !header.Type.IsValid() {
+		return nil, 0, fmt.Errorf("%w %d at offset %d", ErrInvalidOBUType, header.Type, offset)
+	}
+	if !header.HasSizeField {
+		return nil, 0, fmt.Errorf("%w at offset %d", ErrMissingOBUSizeField, offset)
+	}
+
+	sizeOffset := offset + header.Size()
+	obuSize, n, err := ReadLeb128(data[sizeOffset:])
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w at offset %d", err, sizeOffset)
+	}
+
+	payloadOffset := sizeOffset + int(n) // nolint: gosec // G115
+	if obuSize > uint(len(data)-payloadOffset) {
+		return nil, 0, fmt.Errorf("%w at offset %d: %d > %d", ErrOBUSizeTooLarge, offset, obuSize, len(data)-payloadOffset)
+	}
+
+	return header, payloadOffset + int(obuSize), nil // nolint: gosec // G115
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package obu
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOBUHeader(t *testing.T) {
+	header, err := ParseOBUHeader([]byte{0x36, 0x68})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Header{
+		Type:         TypeFrame,
+		HasExtension: true,
+		HasSizeField: true,
+		TemporalID:   3,
+		SpatialID:    1,
+	}
+	if !reflect.DeepEqual(header, expected) {
+		t.Fatalf("Expected %v, got %v", expected, header)
+	}
+	if header.Size() != 2 {
+		t.Fatalf("Expected header size 2, got %d", header.Size())
+	}
+
+	if _, err = ParseOBUHeader([]byte{0x36}); !errors.Is(err, ErrShortHeader) {
+		t.Fatalf("Expected %v, got %v", ErrShortHeader, err)
+	}
+	if _, err = ParseOBUHeader([]byte{0x8a}); !errors.Is(err, ErrForbiddenBitSet) {
+		t.Fatalf("Expected %v, got %v", ErrForbiddenBitSet, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// Temporal delimiter, sequence header and frame with an extension header.
+	valid := []byte{
+		0x12, 0x00,
+		0x0a, 0x03, 0x00, 0x00, 0x00,
+		0x36, 0x00, 0x02, 0xAA, 0xBB,
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Validate(nil); err != nil {
+		t.Fatalf("Unexpected error for empty temporal unit: %v", err)
+	}
+
+	for name, test := range map[string]struct {
+		data   []byte
+		err    error
+		offset string
+	}{
+		"ReservedType": {
+			data:   []byte{0x12, 0x00, 0x02, 0x00},
+			err:    ErrInvalidOBUType,
+			offset: "offset 2",
+		},
+		"ForbiddenBit": {
+			data:   []byte{0x12, 0x00, 0x8a, 0x00},
+			err:    ErrForbiddenBitSet,
+			offset: "offset 2",
+		},
+		"MissingSizeField": {
+			data:   []byte{0x12, 0x00, 0x30, 0xAA},
+			err:    ErrMissingOBUSizeField,
+			offset: "offset 2",
+		},
+		"TruncatedExtension": {
+			data:   []byte{0x12, 0x00, 0x36},
+			err:    ErrShortHeader,
+			offset: "offset 2",
+		},
+		"TruncatedSize": {
+			data:   []byte{0x12, 0x00, 0x32, 0x80},
+			err:    ErrFailedToReadLEB128,
+			offset: "offset 3",
+		},
+		"SizeTooLarge": {
+			data:   valid[:len(valid)-1],
+			err:    ErrOBUSizeTooLarge,
+			offset: "offset 7",
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := Validate(test.data)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if !strings.Contains(err.Error(), test.offset) {
+				t.Fatalf("Expected error to mention %q, got %v", test.offset, err)
+			}
+		})
+	}
+}
+
+func TestSplitTemporalUnits(t *testing.T) {
+	// Temporal delimiter, sequence header and frame
+	first := []byte{0x12, 0x00, 0x0a, 0x03, 0x00, 0x00, 0x00, 0x32, 0x01, 0xAA}
+	// Temporal delimiter and frame with an extension header
+	second := []byte{0x12, 0x00, 0x36, 0x00, 0x02, 0xAA, 0xBB}
+	// Temporal delimiter only
+	third := []byte{0x12, 0x00}
+
+	data := append(append(append([]byte{}, first...), second...), third...)
+	temporalUnits, err := SplitTemporalUnits(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]byte{first, second, third}; !reflect.DeepEqual(temporalUnits, expected) {
+		t.Fatalf("Expected %v, got %v", expected, temporalUnits)
+	}
+
+	// OBUs preceding the first temporal delimiter form their own temporal unit
+	temporalUnits, err = SplitTemporalUnits(data[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]byte{first[2:], second, third}; !reflect.DeepEqual(temporalUnits, expected) {
+		t.Fatalf("Expected %v, got %v", expected, temporalUnits)
+	}
+
+	if temporalUnits, err = SplitTemporalUnits(nil); err != nil || temporalUnits != nil {
+		t.Fatalf("Expected no temporal units, got %v, %v", temporalUnits, err)
+	}
+
+	_, err = SplitTemporalUnits(data[:len(data)-3])
+	if !errors.Is(err, ErrOBUSizeTooLarge) {
+		t.Fatalf("Expected %v, got %v", ErrOBUSizeTooLarge, err)
+	}
+}
@@ -4,6 +4,8 @@
 package codecs
 
 import (
+	"fmt"
+
 	"github.com/pion/rtp/codecs/av1/obu"
 )
 
@@ -32,71 +34,183 @@ const (
 
 // AV1Payloader payloads AV1 packets.
 type AV1Payloader struct {
+	// MaxPacketsPerTU limits the number of payloads a temporal unit can be
+	// fragmented into. Zero means no limit.
+	MaxPacketsPerTU int
+
 	sequenceHeader []byte
 }
 
 // Payload fragments a AV1 packet across one or more byte arrays.
 // See AV1Packet for description of AV1 Payload Header.
-func (p *AV1Payloader) Payload(mtu uint16, payload []byte) (payloads [][]byte) {
+// If MaxPacketsPerTU is exceeded, no payloads are returned.
+func (p *AV1Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	payloads, _ := p.PayloadErr(mtu, payload)
+
+	return payloads
+}
+
+// PayloadErr fragments a AV1 packet across one or more byte arrays.
+// It returns an error if the temporal unit needs more than MaxPacketsPerTU
+// payloads, leaving the payloader state untouched.
+func (p *AV1Payloader) PayloadErr(mtu uint16, payload []byte) ([][]byte, error) {
+	if err := p.checkPacketCount(mtu, payload); err != nil {
+		return nil, err
+	}
+
+	return p.appendPayloads(nil, mtu, payload), nil
+}
+
+// PayloadAppend fragments a AV1 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls. If MaxPacketsPerTU is
+// exceeded, dst is returned unchanged.
+func (p *AV1Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	if err := p.checkPacketCount(mtu, payload); err != nil {
+		return dst
+	}
+
+	return p.appendPayloads(dst, mtu, payload)
+}
+
+func (p *AV1Payloader) checkPacketCount(mtu uint16, payload []byte) error {
+	if p.MaxPacketsPerTU > 0 {
+		if count := p.PayloadCount(mtu, payload); count > p.MaxPacketsPerTU {
+			return fmt.Errorf("%w: %d > %d", errAV1TooManyPackets, count, p.MaxPacketsPerTU)
+		}
+	}
+
+	return nil
+}
+
+func (p *AV1Payloader) appendPayloads(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads := dst
+	p.fragment(mtu, payload, true, appendPackets(&payloads))
+
+	return payloads
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *AV1Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	p.fragment(mtu, payload, false, countPackets(&count))
+
+	return count
+}
+
+// fragment splits payload into packets written to next. The cached sequence
+// header is only updated if commit is set.
+func (p *AV1Payloader) fragment(mtu uint16, payload []byte, commit bool, next packetSink) {
 	payloadDataIndex := 0
 	payloadDataRemaining := len(payload)
 
 	// Payload Data and MTU is non-zero
 	if mtu <= 0 || payloadDataRemaining <= 0 {
-		return payloads
+		return
 	}
 
 	// Cache Sequence Header and packetize with next payload
 	frameType := (payload[0] & obuFrameTypeMask) >> obuFrameTypeBitshift
 	if frameType == obuFameTypeSequenceHeader {
-		p.sequenceHeader = payload
+		if commit {
+			p.sequenceHeader = payload
+		}
 
-		return payloads
+		return
 	}
 
+	sequenceHeader := p.sequenceHeader
+	first := true
 	for payloadDataRemaining > 0 {
 		obuCount := byte(1)
 		metadataSize := av1PayloaderHeadersize
-		if len(p.sequenceHeader) != 0 {
+		if len(sequenceHeader) != 0 {
 			obuCount++
-			metadataSize += leb128Size + len(p.sequenceHeader)
+			metadataSize += leb128Size + len(sequenceHeader)
 		}
 
-		out := make([]byte, minInt(int(mtu), payloadDataRemaining+metadataSize))
-		outOffset := av1PayloaderHeadersize
-		out[0] = obuCount << wBitshift
+		outSize := minInt(int(mtu), payloadDataRemaining+metadataSize)
+		outBufferRemaining := outSize - metadataSize
+		if outBufferRemaining <= 0 {
+			break
+		}
 
-		if obuCount == 2 {
-			// This Payload contain the start of a Coded Video Sequence
-			out[0] ^= nMask
+		if out := next(outSize); out != nil {
+			out[0] = obuCount << wBitshift
 
-			out[1] = byte(obu.EncodeLEB128(uint(len(p.sequenceHeader))))
-			copy(out[2:], p.sequenceHeader)
+			if obuCount == 2 {
+				// This Payload contain the start of a Coded Video Sequence
+				out[0] ^= nMask
+
+				out[1] = byte(obu.EncodeLEB128(uint(len(sequenceHeader))))
+				copy(out[2:], sequenceHeader)
+			}
 
-			outOffset += leb128Size + len(p.sequenceHeader)
+			copy(out[metadataSize:], payload[payloadDataIndex:payloadDataIndex+outBufferRemaining])
 
-			p.sequenceHeader = nil
+			// Does this Fragment contain an OBU that started in a previous payload
+			if !first {
+				out[0] ^= zMask
+			}
+
+			// This OBU will be continued in next Payload
+			if payloadDataRemaining != outBufferRemaining {
+				out[0] ^= yMask
+			}
 		}
 
-		outBufferRemaining := len(out) - outOffset
-		copy(out[outOffset:], payload[payloadDataIndex:payloadDataIndex+outBufferRemaining])
+		sequenceHeader = nil
+		first = false
 		payloadDataRemaining -= outBufferRemaining
 		payloadDataIndex += outBufferRemaining
+	}
 
-		// Does this Fragment contain an OBU that started in a previous payload
-		if len(payloads) > 0 {
-			out[0] ^= zMask
-		}
+	if commit {
+		p.sequenceHeader = sequenceHeader
+	}
+}
 
-		// This OBU will be continued in next Payload
-		if payloadDataRemaining != 0 {
-			out[0] ^= yMask
-		}
+// TemporalUnitSize returns the number of OBU bytes the payloader emits for a
+// temporal unit in the low overhead bitstream format. OBUs are copied as-is,
+// obu_size fields included, so aggregation headers and a cached sequence
+// header come on top. It returns an error if data is malformed.
+func (*AV1Payloader) TemporalUnitSize(data []byte) (int, error) {
+	if err := obu.Validate(data); err != nil {
+		return 0, err
+	}
 
-		payloads = append(payloads, out)
+	return len(data), nil
+}
+
+// AV1PayloadInfo describes a payload produced by the AV1Payloader together with
+// the aggregation header values chosen for it.
+type AV1PayloadInfo struct {
+	Data []byte
+	Z    bool
+	Y    bool
+	N    bool
+	W    byte
+}
+
+// PayloadWithInfo fragments a AV1 packet like Payload, and also returns the
+// aggregation header values of each payload.
+func (p *AV1Payloader) PayloadWithInfo(mtu uint16, payload []byte) []AV1PayloadInfo {
+	payloads := p.Payload(mtu, payload)
+	if len(payloads) == 0 {
+		return nil
 	}
 
-	return payloads
+	infos := make([]AV1PayloadInfo, len(payloads))
+	for i, out := range payloads {
+		infos[i] = AV1PayloadInfo{
+			Data: out,
+			Z:    ((out[0] & zMask) >> zBitshift) != 0,
+			Y:    ((out[0] & yMask) >> yBitshift) != 0,
+			N:    ((out[0] & nMask) >> nBitshift) != 0,
+			W:    (out[0] & wMask) >> wBitshift,
+		}
+	}
+
+	return infos
 }
 
 // AV1Packet represents a depacketized AV1 RTP Packet
@@ -136,6 +250,12 @@ type AV1Packet struct {
 	// AV1Frame provides the tools to construct a collection of OBUs from a collection of OBU Elements
 	OBUElements [][]byte
 
+	// RejectOBUSizeField makes Unmarshal return an error for OBUs carrying
+	// an obu_size field, which the AV1 RTP specification recommends against.
+	// Such OBUs are accepted by default. The check requires the OBU elements
+	// to be parsed, so it is skipped in zero allocation mode.
+	RejectOBUSizeField bool
+
 	videoDepacketizer
 }
 
@@ -162,11 +282,151 @@ func (p *AV1Packet) Unmarshal(payload []byte) ([]byte, error) {
 			return nil, err
 		}
 		p.OBUElements = obuElements
+
+		if p.RejectOBUSizeField {
+			if err := p.checkOBUSizeFields(); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return payload[1:], nil
 }
 
+// Reset discards the previously parsed OBU elements. It should be called
+// when the SSRC of the depacketized stream changes.
+func (p *AV1Packet) Reset() {
+	p.OBUElements = nil
+}
+
+// FragmentType reports whether payload carries an OBU fragment, according to
+// the Z and Y bits of its aggregation header. When the last OBU element starts
+// an OBU continued in the next packet, isStart is set and the type of that OBU
+// is returned. When the first element ends an OBU from the previous packet,
+// isEnd is set. Neither is set when a single element continues in both
+// directions. The OBU type is only known for starting fragments.
+func (*AV1Packet) FragmentType(payload []byte) (obuType uint8, isStart bool, isEnd bool, ok bool) {
+	if len(payload) == 0 || payload[0]&(zMask|yMask) == 0 {
+		return 0, false, false, false
+	}
+
+	packet := &AV1Packet{}
+	if _, err := packet.Unmarshal(payload); err != nil || len(packet.OBUElements) == 0 {
+		return 0, false, false, false
+	}
+
+	singleElement := len(packet.OBUElements) == 1
+	isStart = packet.Y && !(packet.Z && singleElement)
+	isEnd = packet.Z && !(packet.Y && singleElement)
+
+	if isStart {
+		header, err := obu.ParseOBUHeader(packet.OBUElements[len(packet.OBUElements)-1])
+		if err != nil {
+			return 0, false, false, false
+		}
+		obuType = uint8(header.Type)
+	}
+
+	return obuType, isStart, isEnd, true
+}
+
+// Frames groups the complete OBUs of the packet into frames delimited by
+// temporal delimiter OBUs. Each frame is returned in the low overhead bitstream
+// format, with an obu_size field added to the OBUs lacking one. Padding OBUs
+// are dropped. OBU fragments continuing from the previous packet (Z=1) or into
+// the next one (Y=1) are left out, frame.AV1 reassembles those across packets.
+func (p *AV1Packet) Frames() ([][]byte, error) {
+	elements := p.OBUElements
+	if p.Z && len(elements) > 0 {
+		elements = elements[1:]
+	}
+	if p.Y && len(elements) > 0 {
+		elements = elements[:len(elements)-1]
+	}
+
+	var (
+		frames [][]byte
+		frame  []byte
+	)
+	for _, element := range elements {
+		header, err := obu.ParseOBUHeader(element)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Type == obu.TypePadding {
+			continue
+		}
+
+		if header.Type == obu.TypeTemporalDelimiter && len(frame) > 0 {
+			frames = append(frames, frame)
+			frame = nil
+		}
+		frame = appendOBUWithSizeField(frame, header, element)
+	}
+
+	if len(frame) > 0 {
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// appendOBUWithSizeField appends the OBU to dst, inserting an obu_size field if it has none.
+func appendOBUWithSizeField(dst []byte, header *obu.Header, element []byte) []byte {
+	if header.HasSizeField {
+		return append(dst, element...)
+	}
+
+	headerSize := header.Size()
+	dst = append(dst, element[0]|obu.HasSizeFieldMask)
+	dst = append(dst, element[1:headerSize]...)
+	dst = append(dst, obu.WriteToLeb128(uint(len(element)-headerSize))...) // nolint: gosec // G115
+
+	return append(dst, element[headerSize:]...)
+}
+
+// OBUTypes returns the types of the OBUs starting in the packet, parsed from
+// the headers of OBUElements. The first element is skipped when it continues
+// an OBU from the previous packet.
+func (p *AV1Packet) OBUTypes() ([]obu.Type, error) {
+	elements := p.OBUElements
+	if p.Z && len(elements) > 0 {
+		elements = elements[1:]
+	}
+
+	types := make([]obu.Type, 0, len(elements))
+	for _, element := range elements {
+		header, err := obu.ParseOBUHeader(element)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, header.Type)
+	}
+
+	return types, nil
+}
+
+// checkOBUSizeFields returns an error if an OBU starting in this packet has an obu_size field.
+func (p *AV1Packet) checkOBUSizeFields() error {
+	for i, element := range p.OBUElements {
+		if i == 0 && p.Z {
+			// continuation of an OBU from the previous packet
+			continue
+		}
+
+		header, err := obu.ParseOBUHeader(element)
+		if err != nil {
+			return err
+		}
+		if header.HasSizeField {
+			return fmt.Errorf("%w: OBU element %d", errAV1OBUSizeFieldPresent, i)
+		}
+	}
+
+	return nil
+}
+
 func (p *AV1Packet) parseBody(payload []byte) ([][]byte, error) {
 	if p.OBUElements != nil {
 		return p.OBUElements, nil
@@ -201,5 +461,9 @@ func (p *AV1Packet) parseBody(payload []byte) ([][]byte, error) {
 		currentIndex += obuElementLength
 	}
 
+	if p.W != 0 && len(obuElements) != int(p.W) {
+		return nil, fmt.Errorf("%w: W=%d, got %d OBU elements", errAV1OBUCountMismatch, p.W, len(obuElements))
+	}
+
 	return obuElements, nil
 }
@@ -83,6 +83,182 @@ func TestAV1_Marshal(t *testing.T) { //nolint:cyclop
 	})
 }
 
+func TestAV1_MaxPacketsPerTU(t *testing.T) {
+	frame := make([]byte, 100)
+	sequenceHeader := []byte{0xb, 0xA, 0xB, 0xC}
+
+	payloader := &AV1Payloader{MaxPacketsPerTU: 5}
+	payloader.Payload(100, sequenceHeader)
+
+	payloads, err := payloader.PayloadErr(10, frame)
+	if !errors.Is(err, errAV1TooManyPackets) || payloads != nil {
+		t.Fatalf("Expected %v and no payloads, got %v and %d payloads", errAV1TooManyPackets, err, len(payloads))
+	}
+	if payloads = payloader.Payload(10, frame); payloads != nil {
+		t.Fatalf("Expected no payloads, got %d", len(payloads))
+	}
+
+	// The sequence header is still sent with the next temporal unit that fits
+	payloads, err = payloader.PayloadErr(60, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 2 || payloads[0][0]&nMask == 0 {
+		t.Fatalf("Expected 2 payloads starting with the sequence header, got %d", len(payloads))
+	}
+
+	payloader.MaxPacketsPerTU = 0
+	if payloads = payloader.Payload(10, frame); len(payloads) != 12 {
+		t.Fatalf("Expected 12 payloads without limit, got %d", len(payloads))
+	}
+}
+
+func TestAV1_TemporalUnitSize(t *testing.T) {
+	payloader := &AV1Payloader{}
+
+	for name, test := range map[string]struct {
+		data     []byte
+		expected int
+	}{
+		"Empty": {data: nil, expected: 0},
+		"SingleOBU": {
+			data:     []byte{0x32, 0x03, 0xAA, 0xBB, 0xCC},
+			expected: 5,
+		},
+		"MultipleOBUs": {
+			// Temporal delimiter, sequence header and frame with an extension header
+			data: []byte{
+				0x12, 0x00,
+				0x0a, 0x03, 0x00, 0x00, 0x00,
+				0x36, 0x00, 0x02, 0xAA, 0xBB,
+			},
+			expected: 2 + 5 + 5,
+		},
+		"MultiByteSize": {
+			data:     append([]byte{0x12, 0x00, 0x32, 0x80, 0x01}, make([]byte, 128)...),
+			expected: 2 + 131,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			size, err := payloader.TemporalUnitSize(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != test.expected {
+				t.Fatalf("Expected size %d, got %d", test.expected, size)
+			}
+
+			for _, mtu := range []uint16{10, 50, 1500} {
+				emitted := 0
+				for _, payload := range (&AV1Payloader{}).Payload(mtu, test.data) {
+					emitted += len(payload) - av1PayloaderHeadersize
+				}
+				if emitted != size {
+					t.Fatalf("Expected Payload(%d) to emit %d OBU bytes, got %d", mtu, size, emitted)
+				}
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"MissingSizeField": {0x12, 0x00, 0x30, 0xAA},
+		"SizeTooLarge":     {0x12, 0x00, 0x32, 0x03, 0xAA},
+		"TruncatedSize":    {0x12, 0x00, 0x32, 0x80},
+		"ForbiddenBit":     {0x92, 0x00},
+	} {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			if _, err := payloader.TemporalUnitSize(data); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestAV1_PayloadWithInfo(t *testing.T) {
+	payloader := &AV1Payloader{}
+
+	if infos := payloader.PayloadWithInfo(100, nil); infos != nil {
+		t.Fatal("Expected no payloads for empty input")
+	}
+
+	sequenceHeaderFrame := []byte{0xb, 0xA, 0xB, 0xC}
+	if infos := payloader.PayloadWithInfo(100, sequenceHeaderFrame); len(infos) != 0 {
+		t.Fatal("Sequence Header was not properly cached")
+	}
+
+	frame := []byte{0x00, 0x01, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, 0x9, 0xA, 0xB}
+	infos := payloader.PayloadWithInfo(8, frame)
+	if len(infos) != 3 {
+		t.Fatalf("Expected three payloads, got %d", len(infos))
+	}
+
+	expected := []AV1PayloadInfo{
+		{Z: false, Y: true, N: true, W: 2},
+		{Z: true, Y: true, N: false, W: 1},
+		{Z: true, Y: false, N: false, W: 1},
+	}
+	for i, info := range infos {
+		packet := AV1Packet{}
+		if _, err := packet.Unmarshal(info.Data); err != nil {
+			t.Fatal(err)
+		}
+		if info.Z != packet.Z || info.Y != packet.Y || info.N != packet.N || info.W != packet.W {
+			t.Errorf("Payload %d: info %+v does not match aggregation header %+v", i, info, packet)
+		}
+		if info.Z != expected[i].Z || info.Y != expected[i].Y || info.N != expected[i].N || info.W != expected[i].W {
+			t.Errorf("Payload %d: expected %+v, got %+v", i, expected[i], info)
+		}
+	}
+}
+
+func TestAV1_Reset(t *testing.T) {
+	packet := &AV1Packet{}
+	if _, err := packet.Unmarshal([]byte{0x10, 0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+
+	packet.Reset()
+	if packet.OBUElements != nil {
+		t.Fatal("Reset should discard the OBU elements")
+	}
+
+	if _, err := packet.Unmarshal([]byte{0x10, 0x03}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(packet.OBUElements, [][]byte{{0x03}}) {
+		t.Fatalf("Unexpected OBU elements after Reset, %v", packet.OBUElements)
+	}
+}
+
+func TestAV1_Unmarshal_OBUSizeField(t *testing.T) {
+	withSizeField := []byte{0x20, 0x03, 0x32, 0x01, 0xAA, 0x30, 0xBB}
+	withoutSizeField := []byte{0x20, 0x02, 0x30, 0xAA, 0x30, 0xBB}
+	// The first element continues an OBU, so its first byte is not an OBU header
+	continuation := []byte{0xA0, 0x02, 0x32, 0xAA, 0x30, 0xBB}
+
+	for _, input := range [][]byte{withSizeField, withoutSizeField, continuation} {
+		if _, err := (&AV1Packet{}).Unmarshal(input); err != nil {
+			t.Fatalf("Unexpected error for %v by default: %v", input, err)
+		}
+	}
+
+	strict := &AV1Packet{RejectOBUSizeField: true}
+	if _, err := strict.Unmarshal(withSizeField); !errors.Is(err, errAV1OBUSizeFieldPresent) {
+		t.Fatalf("Expected %v, got %v", errAV1OBUSizeFieldPresent, err)
+	}
+	for _, input := range [][]byte{withoutSizeField, continuation} {
+		strict = &AV1Packet{RejectOBUSizeField: true}
+		if _, err := strict.Unmarshal(input); err != nil {
+			t.Fatalf("Unexpected error for %v: %v", input, err)
+		}
+		if len(strict.OBUElements) != 2 {
+			t.Fatalf("Expected 2 OBU elements, got %d", len(strict.OBUElements))
+		}
+	}
+}
+
 func TestAV1_Unmarshal_Error(t *testing.T) {
 	for _, test := range []struct {
 		expectedError error
@@ -93,6 +269,8 @@ func TestAV1_Unmarshal_Error(t *testing.T) {
 		{errIsKeyframeAndFragment, []byte{byte(0b10001000), 0x00}},
 		{obu.ErrFailedToReadLEB128, []byte{byte(0b10000000), 0xFF, 0xFF}},
 		{errShortPacket, []byte{byte(0b10000000), 0xFF, 0x0F, 0x00, 0x00}},
+		{errAV1OBUCountMismatch, []byte{byte(0b00100000), 0x01, 0xAA}},
+		{errAV1OBUCountMismatch, []byte{byte(0b00110000), 0x01, 0xAA, 0x01, 0xBB}},
 	} {
 		test := test
 		av1Pkt := &AV1Packet{}
@@ -295,3 +473,128 @@ func TestAV1_Unmarshal(t *testing.T) {
 		t.Fatal("AV1 Unmarshal didn't store the expected results in the packet")
 	}
 }
+
+func TestAV1_Frames(t *testing.T) {
+	t.Run("Single frame", func(t *testing.T) {
+		// Sequence header, padding and frame OBUs without obu_size fields, W=3
+		av1Pkt := &AV1Packet{}
+		if _, err := av1Pkt.Unmarshal([]byte{0x38, 0x02, 0x08, 0xAA, 0x02, 0x78, 0x00, 0x30, 0xBB, 0xCC}); err != nil {
+			t.Fatal(err)
+		}
+
+		frames, err := av1Pkt.Frames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := [][]byte{{0x0a, 0x01, 0xAA, 0x32, 0x02, 0xBB, 0xCC}}
+		if !reflect.DeepEqual(frames, expected) {
+			t.Fatalf("Expected %v, got %v", expected, frames)
+		}
+	})
+
+	t.Run("Temporal delimiters", func(t *testing.T) {
+		// Two temporal units, the second frame has an extension header and an obu_size field
+		av1Pkt := &AV1Packet{}
+		payload := []byte{0x00, 0x01, 0x10, 0x02, 0x30, 0xAA, 0x01, 0x10, 0x05, 0x36, 0x20, 0x01, 0xBB, 0xCC}
+		if _, err := av1Pkt.Unmarshal(payload); err != nil {
+			t.Fatal(err)
+		}
+
+		frames, err := av1Pkt.Frames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := [][]byte{
+			{0x12, 0x00, 0x32, 0x01, 0xAA},
+			{0x12, 0x00, 0x36, 0x20, 0x01, 0xBB, 0xCC},
+		}
+		if !reflect.DeepEqual(frames, expected) {
+			t.Fatalf("Expected %v, got %v", expected, frames)
+		}
+	})
+
+	t.Run("Fragments", func(t *testing.T) {
+		// The first element continues an OBU and the last one continues in the next packet
+		av1Pkt := &AV1Packet{}
+		if _, err := av1Pkt.Unmarshal([]byte{0xF0, 0x01, 0xFF, 0x02, 0x30, 0xAA, 0x30, 0xBB}); err != nil {
+			t.Fatal(err)
+		}
+
+		frames, err := av1Pkt.Frames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := [][]byte{{0x32, 0x01, 0xAA}}
+		if !reflect.DeepEqual(frames, expected) {
+			t.Fatalf("Expected %v, got %v", expected, frames)
+		}
+
+		av1Pkt = &AV1Packet{}
+		if _, err = av1Pkt.Unmarshal([]byte{0xD0, 0xFF, 0xFF}); err != nil {
+			t.Fatal(err)
+		}
+		if frames, err = av1Pkt.Frames(); err != nil || frames != nil {
+			t.Fatalf("Expected no frames, got %v, %v", frames, err)
+		}
+	})
+
+	t.Run("Invalid OBU", func(t *testing.T) {
+		av1Pkt := &AV1Packet{}
+		if _, err := av1Pkt.Unmarshal([]byte{0x10, 0x80}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := av1Pkt.Frames(); !errors.Is(err, obu.ErrForbiddenBitSet) {
+			t.Fatalf("Expected %v, got %v", obu.ErrForbiddenBitSet, err)
+		}
+	})
+}
+
+func TestAV1_OBUTypes(t *testing.T) {
+	for name, test := range map[string]struct {
+		payload  []byte
+		expected []obu.Type
+	}{
+		"SequenceHeaderAndFrame": {
+			payload:  []byte{0x28, 0x02, 0x08, 0xAA, 0x30, 0xBB},
+			expected: []obu.Type{obu.TypeSequenceHeader, obu.TypeFrame},
+		},
+		"TemporalDelimiters": {
+			payload: []byte{0x00, 0x01, 0x10, 0x02, 0x30, 0xAA, 0x01, 0x10, 0x03, 0x18, 0xBB, 0xCC},
+			expected: []obu.Type{
+				obu.TypeTemporalDelimiter, obu.TypeFrame, obu.TypeTemporalDelimiter, obu.TypeFrameHeader,
+			},
+		},
+		"ContinuationSkipped": {
+			payload:  []byte{0xE0, 0x01, 0xFF, 0x30, 0xAA},
+			expected: []obu.Type{obu.TypeFrame},
+		},
+		"OnlyContinuation": {
+			payload:  []byte{0x90, 0xFF, 0xFF},
+			expected: []obu.Type{},
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			av1Pkt := &AV1Packet{}
+			if _, err := av1Pkt.Unmarshal(test.payload); err != nil {
+				t.Fatal(err)
+			}
+
+			types, err := av1Pkt.OBUTypes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(types, test.expected) {
+				t.Fatalf("Expected %v, got %v", test.expected, types)
+			}
+		})
+	}
+
+	av1Pkt := &AV1Packet{}
+	if _, err := av1Pkt.Unmarshal([]byte{0x10, 0x80}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := av1Pkt.OBUTypes(); !errors.Is(err, obu.ErrForbiddenBitSet) {
+		t.Fatalf("Expected %v, got %v", obu.ErrForbiddenBitSet, err)
+	}
+}
@@ -11,6 +11,31 @@ func minInt(a, b int) int {
 	return b
 }
 
+// packetSink returns the buffer to write the next packet of size bytes into, or
+// nil to only count it. Payloaders produce their payloads and their count with
+// the same loop, given a sink from appendPackets or countPackets.
+type packetSink func(size int) []byte
+
+// appendPackets returns a packetSink allocating each packet and appending it
+// to payloads.
+func appendPackets(payloads *[][]byte) packetSink {
+	return func(size int) []byte {
+		out := make([]byte, size)
+		*payloads = append(*payloads, out)
+
+		return out
+	}
+}
+
+// countPackets returns a packetSink counting the packets in count.
+func countPackets(count *int) packetSink {
+	return func(int) []byte {
+		*count++
+
+		return nil
+	}
+}
+
 // audioDepacketizer is a mixin for audio codec depacketizers.
 type audioDepacketizer struct{}
 
@@ -27,6 +52,9 @@ type videoDepacketizer struct {
 	zeroAllocation bool
 }
 
+// IsPartitionTail checks if this is the last packet of a frame. Video
+// depacketizers report the marker bit, or the end of frame bit of the payload
+// for codecs having one.
 func (d *videoDepacketizer) IsPartitionTail(marker bool, _ []byte) bool {
 	return marker
 }
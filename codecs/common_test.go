@@ -250,3 +250,147 @@ func TestZeroAllocations(t *testing.T) { //nolint:maintidx
 		}
 	}
 }
+
+func TestPayloadCount(t *testing.T) {
+	type payloadCounter interface {
+		Payload(mtu uint16, payload []byte) [][]byte
+		PayloadCount(mtu uint16, payload []byte) int
+	}
+
+	largeNALU := make([]byte, 300)
+	largeNALU[0] = 0x65
+	h264Stream := append([]byte{
+		0x00, 0x00, 0x00, 0x01, 0x09, 0xF0,
+		0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0xc0, 0x1f,
+		0x00, 0x00, 0x00, 0x01, 0x68, 0x1a, 0x34,
+		0x00, 0x00, 0x01,
+	}, largeNALU...)
+
+	largeH265NALU := make([]byte, 300)
+	largeH265NALU[0], largeH265NALU[1] = 0x26, 0x01
+	h265Stream := append([]byte{
+		0x00, 0x00, 0x00, 0x01, 0x40, 0x01, 0x0c, 0x01,
+		0x00, 0x00, 0x00, 0x01, 0x42, 0x01, 0x01, 0x01,
+		0x00, 0x00, 0x00, 0x01, 0x44, 0x01, 0xc1, 0x72,
+		0x00, 0x00, 0x01,
+	}, largeH265NALU...)
+
+	vp9KeyFrame := append([]byte{0x82, 0x49, 0x83, 0x42, 0x00, 0x77, 0xf0, 0x32, 0x34}, make([]byte, 200)...)
+	vp9InterFrame := append([]byte{0x86, 0x00, 0x40, 0x92, 0xe1, 0x31, 0x42, 0x8c, 0xc0, 0x40}, make([]byte, 200)...)
+
+	tests := map[string]struct {
+		payloader func() payloadCounter
+		payloads  [][]byte
+	}{
+		"H264": {
+			payloader: func() payloadCounter { return &H264Payloader{} },
+			payloads:  [][]byte{h264Stream, {0x67, 0x42}, {0x68, 0x1a}, largeNALU, {}},
+		},
+		"H265": {
+			payloader: func() payloadCounter { return &H265Payloader{} },
+			payloads:  [][]byte{h265Stream, largeH265NALU},
+		},
+		"H265 DONL": {
+			payloader: func() payloadCounter { return &H265Payloader{AddDONL: true} },
+			payloads:  [][]byte{h265Stream, largeH265NALU},
+		},
+		"H265 SkipAggregation": {
+			payloader: func() payloadCounter { return &H265Payloader{SkipAggregation: true} },
+			payloads:  [][]byte{h265Stream},
+		},
+		"H265 AggregateParameterSets": {
+			payloader: func() payloadCounter { return &H265Payloader{AggregateParameterSets: true} },
+			payloads:  [][]byte{h265Stream, {0x40, 0x01, 0x0c}, {0x42, 0x01, 0x01}, largeH265NALU, h265Stream[:24]},
+		},
+		"H265 ErrorOnFragmentation": {
+			payloader: func() payloadCounter { return &H265Payloader{ErrorOnFragmentation: true} },
+			payloads:  [][]byte{h265Stream},
+		},
+		"VP8": {
+			payloader: func() payloadCounter { return &VP8Payloader{} },
+			payloads:  [][]byte{make([]byte, 200), nil},
+		},
+		"VP8 PictureID": {
+			payloader: func() payloadCounter { return &VP8Payloader{EnablePictureID: true} },
+			payloads:  [][]byte{make([]byte, 200), make([]byte, 200)},
+		},
+		"VP9 flexible": {
+			payloader: func() payloadCounter { return &VP9Payloader{FlexibleMode: true} },
+			payloads:  [][]byte{make([]byte, 200), nil},
+		},
+		"VP9 non-flexible": {
+			payloader: func() payloadCounter { return &VP9Payloader{} },
+			payloads:  [][]byte{vp9KeyFrame, vp9InterFrame, nil},
+		},
+		"AV1": {
+			payloader: func() payloadCounter { return &AV1Payloader{} },
+			payloads:  [][]byte{{0x08, 0x0A, 0x0B, 0x0C}, make([]byte, 200), make([]byte, 200)},
+		},
+		"Opus": {
+			payloader: func() payloadCounter { return &OpusPayloader{} },
+			payloads:  [][]byte{make([]byte, 200), nil},
+		},
+		"G711": {
+			payloader: func() payloadCounter { return &G711Payloader{} },
+			payloads:  [][]byte{make([]byte, 200), {}, nil},
+		},
+		"G722": {
+			payloader: func() payloadCounter { return &G722Payloader{} },
+			payloads:  [][]byte{make([]byte, 200), {}, nil},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for _, mtu := range []uint16{0, 1, 2, 3, 4, 10, 20, 37, 64, 100, 1500} {
+				payloader := test.payloader()
+				for i, payload := range test.payloads {
+					count := payloader.PayloadCount(mtu, payload)
+					if res := payloader.Payload(mtu, payload); count != len(res) {
+						t.Errorf("MTU %d payload %d: PayloadCount returned %d, Payload produced %d", mtu, i, count, len(res))
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("NoAllocations", func(t *testing.T) {
+		for name, count := range map[string]func(){
+			"H264": func() { (&H264Payloader{}).PayloadCount(100, h264Stream) },
+			"H265": func() { (&H265Payloader{}).PayloadCount(100, h265Stream) },
+		} {
+			if allocs := testing.AllocsPerRun(10, count); allocs != 0 {
+				t.Errorf("%s: PayloadCount allocated %f times", name, allocs)
+			}
+		}
+	})
+}
+
+func TestVideoIsPartitionTail(t *testing.T) {
+	type partitionTailChecker interface {
+		IsPartitionTail(marker bool, payload []byte) bool
+	}
+
+	// Every video depacketizer reports the marker bit, or the end of frame bit
+	// of codecs having one. endOfFrame says whether payload has that bit set.
+	for name, test := range map[string]struct {
+		depacketizer partitionTailChecker
+		payload      []byte
+		endOfFrame   bool
+	}{
+		"H264":          {&H264Packet{}, []byte{0x7c, 0x45, 0x01}, false},
+		"H265":          {&H265Packet{}, []byte{0x62, 0x01, 0x53, 0xAA}, false},
+		"VP8":           {&VP8Packet{}, []byte{0x10, 0x00}, false},
+		"VP9":           {&VP9Packet{}, []byte{0x88, 0x01}, false},
+		"VP9EndOfFrame": {&VP9Packet{}, []byte{0x84, 0x01}, true},
+		"AV1":           {&AV1Packet{}, []byte{0x10, 0x01}, false},
+	} {
+		if !test.depacketizer.IsPartitionTail(true, test.payload) {
+			t.Errorf("%s: packet with the marker bit should end the frame", name)
+		}
+		if got := test.depacketizer.IsPartitionTail(false, test.payload); got != test.endOfFrame {
+			t.Errorf("%s: without the marker bit, expected %v, got %v", name, test.endOfFrame, got)
+		}
+	}
+}
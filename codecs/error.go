@@ -11,9 +11,19 @@ var (
 	errTooManyPDiff         = errors.New("too many PDiff")
 	errTooManySpatialLayers = errors.New("too many spatial layers")
 	errUnhandledNALUType    = errors.New("NALU Type is unhandled")
+	errH264IncompleteFUA    = errors.New("FU-A fragment lost, incomplete NALU discarded")
+	errH264NALUExceedsMTU   = errors.New("h264 NALU exceeds MTU in single NALU mode")
+	errH264InvalidFUA       = errors.New("invalid set of FU-A fragments")
 
 	// AV1 Errors.
 	errIsKeyframeAndFragment = errors.New(
 		"bits Z and N are set. Not possible to have OBU be tail fragment and be keyframe",
 	)
+	errAV1OBUSizeFieldPresent = errors.New("OBU has a size field")
+	errAV1OBUCountMismatch    = errors.New("number of OBU elements does not match W")
+	errAV1TooManyPackets      = errors.New("temporal unit needs too many packets")
 )
+
+// ErrPartialFrameTooLong is returned when a fragmented NALU or OBU spans more
+// packets than allowed by MaxPartialPackets.
+var ErrPartialFrameTooLong = errors.New("partial frame spans too many packets")
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+// FragmentTyper is implemented by packets of codecs that fragment their units
+// across several RTP payloads.
+type FragmentTyper interface {
+	// FragmentType reports whether payload carries a fragment and, if so, the
+	// codec specific type of the fragmented unit and whether the fragment
+	// starts or ends it. ok is false when payload carries no fragment.
+	FragmentType(payload []byte) (codecSpecificType uint8, isStart bool, isEnd bool, ok bool)
+}
+
+var (
+	_ FragmentTyper = (*H264Packet)(nil)
+	_ FragmentTyper = (*H265Packet)(nil)
+	_ FragmentTyper = (*AV1Packet)(nil)
+)
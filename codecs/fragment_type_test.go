@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "testing"
+
+func TestFragmentType(t *testing.T) {
+	type result struct {
+		codecSpecificType uint8
+		isStart, isEnd    bool
+		ok                bool
+	}
+
+	for name, test := range map[string]struct {
+		typer    FragmentTyper
+		payload  []byte
+		expected result
+	}{
+		"H264Start":    {&H264Packet{}, []byte{0x7c, 0x85, 0xaa}, result{5, true, false, true}},
+		"H264Middle":   {&H264Packet{}, []byte{0x7c, 0x05, 0xaa}, result{5, false, false, true}},
+		"H264End":      {&H264Packet{}, []byte{0x7c, 0x45, 0xaa}, result{5, false, true, true}},
+		"H264FUB":      {&H264Packet{}, []byte{0x7d, 0x81, 0x00, 0x01, 0xaa}, result{1, true, false, true}},
+		"H264Single":   {&H264Packet{}, []byte{0x65, 0x88, 0x84}, result{}},
+		"H264Short":    {&H264Packet{}, []byte{0x7c}, result{}},
+		"H265Start":    {&H265Packet{}, []byte{0x62, 0x01, 0x93, 0xaa}, result{19, true, false, true}},
+		"H265Middle":   {&H265Packet{}, []byte{0x62, 0x01, 0x13, 0xaa}, result{19, false, false, true}},
+		"H265End":      {&H265Packet{}, []byte{0x62, 0x01, 0x53, 0xaa}, result{19, false, true, true}},
+		"H265Single":   {&H265Packet{}, []byte{0x26, 0x01, 0xaa}, result{}},
+		"H265Short":    {&H265Packet{}, []byte{0x62, 0x01}, result{}},
+		"AV1Start":     {&AV1Packet{}, []byte{0x50, 0x30, 0xaa}, result{6, true, false, true}},
+		"AV1Middle":    {&AV1Packet{}, []byte{0xd0, 0xaa}, result{0, false, false, true}},
+		"AV1End":       {&AV1Packet{}, []byte{0x90, 0xaa}, result{0, false, true, true}},
+		"AV1EndStart":  {&AV1Packet{}, []byte{0xe0, 0x01, 0xaa, 0x18, 0xbb}, result{3, true, true, true}},
+		"AV1Complete":  {&AV1Packet{}, []byte{0x10, 0x30, 0xaa}, result{}},
+		"AV1Empty":     {&AV1Packet{}, []byte{}, result{}},
+		"AV1Malformed": {&AV1Packet{}, []byte{0x40, 0x05, 0xaa}, result{}},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var actual result
+			actual.codecSpecificType, actual.isStart, actual.isEnd, actual.ok = test.typer.FragmentType(test.payload)
+			if actual != test.expected {
+				t.Fatalf("Expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -3,24 +3,50 @@
 
 package codecs
 
-// G711Payloader payloads G711 packets.
+// G711Payloader payloads G711 (PCMU/PCMA) packets.
+// G711 carries one byte per sample at a clock rate of 8000 Hz, so payloads
+// can be split at any byte boundary.
 type G711Payloader struct{}
 
-// Payload fragments an G711 packet across one or more byte arrays.
+// Payload fragments an G711 packet across one or more byte arrays of at most mtu bytes.
 func (p *G711Payloader) Payload(mtu uint16, payload []byte) [][]byte {
-	var out [][]byte
+	return p.PayloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments an G711 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls.
+func (p *G711Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads := dst
+	packetizeBytes(mtu, payload, appendPackets(&payloads))
+
+	return payloads
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *G711Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	packetizeBytes(mtu, payload, countPackets(&count))
+
+	return count
+}
+
+// packetizeBytes splits payload into packets of at most mtu bytes written to
+// next, for codecs whose payloads can be split at any byte. An empty payload
+// gives a single empty packet, and a nil one none.
+func packetizeBytes(mtu uint16, payload []byte, next packetSink) {
 	if payload == nil || mtu == 0 {
-		return out
+		return
 	}
 
-	for len(payload) > int(mtu) {
-		o := make([]byte, mtu)
-		copy(o, payload[:mtu])
-		payload = payload[mtu:]
-		out = append(out, o)
-	}
-	o := make([]byte, len(payload))
-	copy(o, payload)
+	for {
+		size := minInt(int(mtu), len(payload))
+		if out := next(size); out != nil {
+			copy(out, payload[:size])
+		}
 
-	return append(out, o)
+		payload = payload[size:]
+		if len(payload) == 0 {
+			return
+		}
+	}
 }
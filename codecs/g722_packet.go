@@ -4,23 +4,29 @@
 package codecs
 
 // G722Payloader payloads G722 packets.
+// G722 encodes 16000 Hz audio in one byte per two samples, but for historical
+// reasons its RTP clock rate is 8000 Hz (RFC 3551 Section 4.5.2), so the RTP
+// timestamp advances by one per byte of payload, like G711.
 type G722Payloader struct{}
 
-// Payload fragments an G722 packet across one or more byte arrays.
+// Payload fragments an G722 packet across one or more byte arrays of at most mtu bytes.
 func (p *G722Payloader) Payload(mtu uint16, payload []byte) [][]byte {
-	var out [][]byte
-	if payload == nil || mtu == 0 {
-		return out
-	}
+	return p.PayloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments an G722 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls.
+func (p *G722Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads := dst
+	packetizeBytes(mtu, payload, appendPackets(&payloads))
+
+	return payloads
+}
 
-	for len(payload) > int(mtu) {
-		o := make([]byte, mtu)
-		copy(o, payload[:mtu])
-		payload = payload[mtu:]
-		out = append(out, o)
-	}
-	o := make([]byte, len(payload))
-	copy(o, payload)
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *G722Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	packetizeBytes(mtu, payload, countPackets(&count))
 
-	return append(out, o)
+	return count
 }
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import (
+	"bytes"
+
+	"github.com/pion/rtp/codecs/av1/obu"
+)
+
+const (
+	vp8KeyFrameStartCodeOffset = 3
+	vp8ReservedBitsMask        = 0x48
+	vp8ExtensionReservedMask   = 0x0F
+
+	av1ReservedBitsMask   = 0x07
+	obuReservedBitMask    = 0x01
+	h264ForbiddenBitMask  = 0x80
+	h265NaluMaxIRAPType   = 21
+	h265NaluMaxNonVCLType = 40
+)
+
+// nolint:gochecknoglobals
+var vp8KeyFrameStartCode = []byte{0x9d, 0x01, 0x2a}
+
+// GuessCodec guesses the codec of an RTP payload from its first bytes, for
+// diagnostics, and returns its MIME type in lower case, such as "video/h264",
+// or an empty string when the payload matches none of the supported codecs.
+//
+// The guess is inherently heuristic. Payload formats have few fixed bits, so a
+// payload often matches several codecs and GuessCodec picks the first match
+// among, in order: VP8 key frames, H265, AV1, H264, VP8 and VP9. Only video
+// codecs are detected. Payloads continuing an AV1 OBU or a VP8 partition from
+// a previous packet are detected poorly, and a single payload should not be
+// trusted when the codec matters: guesses over several packets are more reliable.
+func GuessCodec(payload []byte) string {
+	switch {
+	case len(payload) < 2:
+		return ""
+	case isVP8KeyFrame(payload):
+		return mimeTypeVP8
+	case looksLikeH265(payload):
+		return mimeTypeH265
+	case looksLikeAV1(payload):
+		return mimeTypeAV1
+	case looksLikeH264(payload):
+		return mimeTypeH264
+	case looksLikeVP8(payload):
+		return mimeTypeVP8
+	case looksLikeVP9(payload):
+		return mimeTypeVP9
+	default:
+		return ""
+	}
+}
+
+// isVP8KeyFrame checks for the start of a VP8 key frame, which carries a start code.
+func isVP8KeyFrame(payload []byte) bool {
+	packet := &VP8Packet{}
+	if _, err := packet.Unmarshal(payload); err != nil || packet.S != 1 || packet.PID != 0 {
+		return false
+	}
+
+	frame := packet.Payload
+
+	return len(frame) >= vp8KeyFrameStartCodeOffset+len(vp8KeyFrameStartCode) &&
+		frame[0]&0x01 == 0 &&
+		bytes.Equal(frame[vp8KeyFrameStartCodeOffset:][:len(vp8KeyFrameStartCode)], vp8KeyFrameStartCode)
+}
+
+// looksLikeH265 checks for a base layer NALU header with a known NALU type.
+func looksLikeH265(payload []byte) bool {
+	header := newH265NALUHeader(payload[0], payload[1])
+	if header.F() || header.LayerID() != 0 || header.TID() == 0 {
+		return false
+	}
+
+	switch naluType := header.Type(); {
+	case naluType <= h265NaluMaxIRAPType:
+		// Types 10 to 15 are reserved
+		return naluType < 10 || naluType > 15
+	case naluType >= h265NaluFirstNonVCLType && naluType <= h265NaluMaxNonVCLType:
+		return true
+	default:
+		return naluType == h265NaluAggregationPacketType ||
+			naluType == h265NaluFragmentationUnitType ||
+			naluType == h265NaluPACIPacketType
+	}
+}
+
+// looksLikeAV1 checks for an aggregation header followed by a valid OBU header.
+// Payloads starting with an OBU fragment are not recognized.
+func looksLikeAV1(payload []byte) bool {
+	if payload[0]&zMask != 0 || payload[0]&av1ReservedBitsMask != 0 {
+		return false
+	}
+
+	offset := 1
+	if (payload[0]&wMask)>>wBitshift != 1 {
+		// The first OBU element is preceded by its length
+		length, n, err := obu.ReadLeb128(payload[offset:])
+		if err != nil || length == 0 {
+			return false
+		}
+		offset += int(n) // nolint: gosec // G115
+	}
+	if offset >= len(payload) || payload[offset]&obuReservedBitMask != 0 {
+		return false
+	}
+
+	header, err := obu.ParseOBUHeader(payload[offset:])
+
+	return err == nil && header.Type.IsValid() && header.Type != obu.TypeTemporalDelimiter
+}
+
+// looksLikeH264 checks for a NALU header with a known NALU type.
+func looksLikeH264(payload []byte) bool {
+	if payload[0]&h264ForbiddenBitMask != 0 {
+		return false
+	}
+
+	naluType := H264NALUType(payload[0] & naluTypeBitmask)
+
+	return (naluType >= H264NALUTypeSlice && naluType <= H264NALUTypeFiller) ||
+		(naluType >= H264NALUTypeSTAPA && naluType <= H264NALUTypeFUB)
+}
+
+// looksLikeVP8 checks the reserved bits of the VP8 payload descriptor.
+func looksLikeVP8(payload []byte) bool {
+	if payload[0]&vp8ReservedBitsMask != 0 {
+		return false
+	}
+
+	return payload[0]&0x80 == 0 || payload[1]&vp8ExtensionReservedMask == 0
+}
+
+// looksLikeVP9 checks that the VP9 payload descriptor can be parsed.
+func looksLikeVP9(payload []byte) bool {
+	_, err := (&VP9Packet{}).Unmarshal(payload)
+
+	return err == nil
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "testing"
+
+func TestGuessCodec(t *testing.T) {
+	for name, test := range map[string]struct {
+		payload  []byte
+		expected string
+	}{
+		"Empty":         {payload: nil, expected: ""},
+		"TooShort":      {payload: []byte{0x67}, expected: ""},
+		"H264SPS":       {payload: []byte{0x67, 0x42, 0xc0, 0x1f}, expected: mimeTypeH264},
+		"H264IDR":       {payload: []byte{0x65, 0x88, 0x84, 0x00}, expected: mimeTypeH264},
+		"H264STAPA":     {payload: []byte{0x78, 0x00, 0x04, 0x67, 0x42, 0xc0, 0x1f}, expected: mimeTypeH264},
+		"H264FUA":       {payload: []byte{0x7c, 0x85, 0xb8, 0x00}, expected: mimeTypeH264},
+		"H265VPS":       {payload: []byte{0x40, 0x01, 0x0c, 0x01, 0xff, 0xff}, expected: mimeTypeH265},
+		"H265IDR":       {payload: []byte{0x26, 0x01, 0xaf, 0x06}, expected: mimeTypeH265},
+		"H265AP":        {payload: []byte{0x60, 0x01, 0x00, 0x02, 0x40, 0x01}, expected: mimeTypeH265},
+		"H265FU":        {payload: []byte{0x62, 0x01, 0x93, 0xaf, 0x0d}, expected: mimeTypeH265},
+		"VP8KeyFrame":   {payload: []byte{0x10, 0x50, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x80}, expected: mimeTypeVP8},
+		"VP8PictureID":  {payload: []byte{0x90, 0x80, 0x05, 0x50, 0x02, 0x00, 0x9d, 0x01, 0x2a}, expected: mimeTypeVP8},
+		"VP8InterFrame": {payload: []byte{0x10, 0x31, 0x02, 0x00, 0xaa}, expected: mimeTypeVP8},
+		"VP8Extended":   {payload: []byte{0x90, 0x80, 0x05, 0x31, 0x02}, expected: mimeTypeVP8},
+		"VP9KeyFrame":   {payload: []byte{0x8a, 0x01, 0x10, 0x01, 0x40, 0x00, 0xf0, 0x82, 0x49}, expected: mimeTypeVP9},
+		"VP9InterFrame": {payload: []byte{0xc8, 0x02, 0x86, 0x00, 0x40}, expected: mimeTypeVP9},
+		"AV1SingleOBU":  {payload: []byte{0x18, 0x08, 0x00, 0x00, 0x00, 0x24}, expected: mimeTypeAV1},
+		"AV1OBULength": {
+			payload:  []byte{0x20, 0x0a, 0x30, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30},
+			expected: mimeTypeAV1,
+		},
+		"AV1FrameHeader": {payload: []byte{0x10, 0x18, 0xaa, 0xbb}, expected: mimeTypeAV1},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if guess := GuessCodec(test.payload); guess != test.expected {
+				t.Fatalf("Expected %q, got %q", test.expected, guess)
+			}
+		})
+	}
+}
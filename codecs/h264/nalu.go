@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package h264
+
+import "github.com/pion/rtp/codecs/internal/annexb"
+
+const naluTypeBitmask = 0x1F
+
+// NALTypes returns the types of the NAL units of an Annex B byte stream, in
+// order. A keyframe typically yields 7, 8, 5 (SPS, PPS, IDR slice).
+func NALTypes(stream []byte) []uint8 {
+	var types []uint8
+
+	annexb.EmitNALUs(stream, func(nalu []byte) {
+		if len(nalu) == 0 {
+			return
+		}
+		types = append(types, nalu[0]&naluTypeBitmask)
+	})
+
+	return types
+}
@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package h264
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNALTypes(t *testing.T) {
+	keyframe := []byte{
+		0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x1f, // SPS
+		0x00, 0x00, 0x00, 0x01, 0x68, 0xce, 0x3c, 0x80, // PPS
+		0x00, 0x00, 0x01, 0x65, 0x88, 0x84, 0x00, // IDR slice
+	}
+
+	if types := NALTypes(keyframe); !reflect.DeepEqual(types, []uint8{7, 8, 5}) {
+		t.Fatalf("Expected [7 8 5], got %v", types)
+	}
+
+	// A 3-byte start code followed by a 4-byte one
+	mixed := []byte{
+		0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x1f, // SPS
+		0x00, 0x00, 0x00, 0x01, 0x68, 0xce, 0x3c, 0x80, // PPS
+	}
+	if types := NALTypes(mixed); !reflect.DeepEqual(types, []uint8{7, 8}) {
+		t.Fatalf("Expected [7 8], got %v", types)
+	}
+
+	if types := NALTypes(nil); types != nil {
+		t.Fatalf("Expected no types for an empty buffer, got %v", types)
+	}
+}
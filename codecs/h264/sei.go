@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+// Package h264 implements tools for working with H.264 bitstreams.
+package h264
+
+import "github.com/pion/rtp/codecs/internal/annexb"
+
+const (
+	seiNALUType = 6
+
+	// payloadType and payloadSize are coded as a run of 0xFF bytes, each adding 255
+	seiSizeContinuation = 0xFF
+	rbspTrailingBits    = 0x80
+)
+
+// ExtractSEI returns the payloads of all SEI messages found in the SEI NAL
+// units of an Annex B byte stream, in order. Emulation prevention bytes are
+// removed. Parsing of a NAL unit stops at the first truncated SEI message.
+func ExtractSEI(stream []byte) [][]byte {
+	var payloads [][]byte
+
+	annexb.EmitNALUs(stream, func(nalu []byte) {
+		if len(nalu) == 0 || nalu[0]&naluTypeBitmask != seiNALUType {
+			return
+		}
+
+		payloads = appendSEIPayloads(payloads, annexb.RemoveEmulationPrevention(nalu[1:]))
+	})
+
+	return payloads
+}
+
+// appendSEIPayloads parses the sei_message() syntax structures of an SEI RBSP.
+func appendSEIPayloads(payloads [][]byte, rbsp []byte) [][]byte {
+	offset := 0
+	for offset < len(rbsp) && !(offset == len(rbsp)-1 && rbsp[offset] == rbspTrailingBits) {
+		var ok bool
+		if _, offset, ok = readSEIValue(rbsp, offset); !ok {
+			return payloads
+		}
+
+		var payloadSize int
+		if payloadSize, offset, ok = readSEIValue(rbsp, offset); !ok {
+			return payloads
+		}
+		if len(rbsp)-offset < payloadSize {
+			return payloads
+		}
+
+		payloads = append(payloads, rbsp[offset:offset+payloadSize])
+		offset += payloadSize
+	}
+
+	return payloads
+}
+
+// readSEIValue reads a payloadType or payloadSize value starting at offset.
+func readSEIValue(rbsp []byte, offset int) (value int, next int, ok bool) {
+	for offset < len(rbsp) {
+		b := rbsp[offset]
+		offset++
+		value += int(b)
+		if b != seiSizeContinuation {
+			return value, offset, true
+		}
+	}
+
+	return 0, offset, false
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package h264
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestExtractSEI(t *testing.T) {
+	largePayload := bytes.Repeat([]byte{0xAB}, 300)
+
+	stream := []byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x1f} // SPS
+	// SEI with two messages: user data unregistered and a 300 byte payload
+	stream = append(stream, 0x00, 0x00, 0x00, 0x01, 0x06)
+	stream = append(stream, 0x05, 0x03, 0x01, 0x02, 0x03)
+	stream = append(stream, 0x04, 0xFF, 0x2D)
+	stream = append(stream, largePayload...)
+	stream = append(stream, 0x80)
+	// SEI with an emulation prevention byte in its payload
+	stream = append(stream, 0x00, 0x00, 0x01, 0x06, 0x05, 0x03, 0x00, 0x00, 0x03, 0x01, 0x80)
+	// IDR slice
+	stream = append(stream, 0x00, 0x00, 0x01, 0x65, 0x88, 0x84)
+
+	expected := [][]byte{
+		{0x01, 0x02, 0x03},
+		largePayload,
+		{0x00, 0x00, 0x01},
+	}
+	if payloads := ExtractSEI(stream); !reflect.DeepEqual(payloads, expected) {
+		t.Fatalf("Expected %v, got %v", expected, payloads)
+	}
+}
+
+func TestExtractSEI_Truncated(t *testing.T) {
+	stream := []byte{
+		0x00, 0x00, 0x00, 0x01, 0x06, 0x05, 0x01, 0xAA, 0x05, 0x10, 0xBB,
+	}
+
+	expected := [][]byte{{0xAA}}
+	if payloads := ExtractSEI(stream); !reflect.DeepEqual(payloads, expected) {
+		t.Fatalf("Expected %v, got %v", expected, payloads)
+	}
+
+	if payloads := ExtractSEI([]byte{0x00, 0x00, 0x01, 0x65, 0x88}); payloads != nil {
+		t.Fatalf("Expected no SEI payloads, got %v", payloads)
+	}
+}
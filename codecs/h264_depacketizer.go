@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+// H264AccessUnit is an access unit reassembled by H264Depacketizer.
+type H264AccessUnit struct {
+	// Timestamp is the RTP timestamp of the packets of the access unit.
+	Timestamp uint32
+	// Data holds the NALUs of the access unit in the AVCC format, each one
+	// preceded by its length as a 4-byte big-endian integer.
+	Data []byte
+}
+
+// H264Depacketizer reassembles H264 access units from RTP payloads, in the
+// AVCC format used by MP4 samples. Unlike H264Packet, it keeps the NALUs of an
+// access unit until the packet with the marker bit. It should be used for a
+// single RTP stream.
+type H264Depacketizer struct {
+	packet    H264Packet
+	data      []byte
+	timestamp uint32
+	started   bool
+}
+
+// Push processes the payload of an RTP packet with its timestamp and marker
+// bit, and returns the access unit completed by the packet with the marker
+// bit set, if any. The NALUs of an access unit whose last packet was lost are
+// discarded when a packet with a different timestamp arrives.
+func (d *H264Depacketizer) Push(payload []byte, timestamp uint32, marker bool) (*H264AccessUnit, error) {
+	if d.started && timestamp != d.timestamp {
+		d.Reset()
+	}
+	d.started = true
+	d.timestamp = timestamp
+
+	d.packet.IsAVC = true
+	nalus, err := d.packet.Unmarshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	d.data = append(d.data, nalus...)
+
+	if !marker {
+		return nil, nil //nolint:nilnil
+	}
+
+	data := d.data
+	d.Reset()
+	if len(data) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &H264AccessUnit{Timestamp: timestamp, Data: data}, nil
+}
+
+// Reset discards the partially reassembled access unit. It should be called
+// when the SSRC of the depacketized stream changes.
+func (d *H264Depacketizer) Reset() {
+	d.packet.Reset()
+	d.data = nil
+	d.started = false
+}
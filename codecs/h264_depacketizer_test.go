@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestH264Depacketizer(t *testing.T) {
+	sps := []byte{0x67, 0x42, 0x00, 0x1f}
+	pps := []byte{0x68, 0xce, 0x3c, 0x80}
+	idr := append([]byte{0x65}, bytes.Repeat([]byte{0xAA}, 30)...)
+
+	var frame []byte
+	for _, nalu := range [][]byte{sps, pps, idr} {
+		frame = append(frame, 0x00, 0x00, 0x00, 0x01)
+		frame = append(frame, nalu...)
+	}
+
+	// STAP-A with SPS and PPS, then the IDR fragmented in FU-A packets
+	payloads := (&H264Payloader{}).Payload(13, frame)
+	if len(payloads) < 3 {
+		t.Fatalf("Expected the access unit to span several packets, got %d", len(payloads))
+	}
+
+	depacketizer := &H264Depacketizer{}
+	var accessUnit *H264AccessUnit
+	for i, payload := range payloads {
+		au, err := depacketizer.Push(payload, 3000, i == len(payloads)-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < len(payloads)-1 && au != nil {
+			t.Fatalf("Unexpected access unit before the marker bit at packet %d", i)
+		}
+		accessUnit = au
+	}
+
+	if accessUnit == nil {
+		t.Fatal("Expected an access unit")
+	}
+	if accessUnit.Timestamp != 3000 {
+		t.Errorf("Expected timestamp 3000, got %d", accessUnit.Timestamp)
+	}
+
+	var expected []byte
+	for _, nalu := range [][]byte{sps, pps, idr} {
+		expected = append(expected, 0x00, 0x00, 0x00, byte(len(nalu)))
+		expected = append(expected, nalu...)
+	}
+	if !bytes.Equal(accessUnit.Data, expected) {
+		t.Errorf("Expected %v, got %v", expected, accessUnit.Data)
+	}
+}
+
+func TestH264Depacketizer_LostMarker(t *testing.T) {
+	depacketizer := &H264Depacketizer{}
+
+	// The packet with the marker bit of the first access unit is lost
+	if au, err := depacketizer.Push([]byte{0x01, 0xAA}, 1000, false); err != nil || au != nil {
+		t.Fatalf("Unexpected result %v, %v", au, err)
+	}
+
+	au, err := depacketizer.Push([]byte{0x01, 0xBB}, 2000, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if au == nil || au.Timestamp != 2000 || !bytes.Equal(au.Data, []byte{0x00, 0x00, 0x00, 0x02, 0x01, 0xBB}) {
+		t.Fatalf("Expected only the second access unit, got %v", au)
+	}
+
+	// A lone FU-A end fragment yields no access unit
+	if au, err = depacketizer.Push([]byte{0x7C, 0x45, 0xCC}, 3000, true); err != nil || au != nil {
+		t.Fatalf("Unexpected result %v, %v", au, err)
+	}
+}
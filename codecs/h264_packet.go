@@ -4,13 +4,21 @@
 package codecs
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
+
+	"github.com/pion/rtp/codecs/internal/annexb"
 )
 
 // H264Payloader payloads H264 packets.
 type H264Payloader struct {
+	// KeepAUDAndFiller makes the payloader packetize access unit delimiter and
+	// filler data NALUs like any other NALU, instead of dropping them.
+	KeepAUDAndFiller bool
+	// SingleNALUMode makes PayloadErr return an error instead of emitting
+	// FU-A fragments when a NALU does not fit in the MTU.
+	SingleNALUMode bool
+
 	spsNalu, ppsNalu []byte
 }
 
@@ -31,49 +39,129 @@ const (
 	naluRefIdcBitmask = 0x60
 	fuStartBitmask    = 0x80
 	fuEndBitmask      = 0x40
+)
+
+// H264NALUType is the type of a H264 NAL Unit, as defined in H.264 Table 7-1
+// and RFC 6184 for the RTP payload specific types.
+type H264NALUType uint8
 
-	outputStapAHeader = 0x78
+// H264 NAL Unit types.
+const (
+	H264NALUTypeSlice         H264NALUType = 1
+	H264NALUTypeSliceDPA      H264NALUType = 2
+	H264NALUTypeSliceDPB      H264NALUType = 3
+	H264NALUTypeSliceDPC      H264NALUType = 4
+	H264NALUTypeIDR           H264NALUType = 5
+	H264NALUTypeSEI           H264NALUType = 6
+	H264NALUTypeSPS           H264NALUType = 7
+	H264NALUTypePPS           H264NALUType = 8
+	H264NALUTypeAUD           H264NALUType = 9
+	H264NALUTypeEndOfSequence H264NALUType = 10
+	H264NALUTypeEndOfStream   H264NALUType = 11
+	H264NALUTypeFiller        H264NALUType = 12
+	H264NALUTypeSTAPA         H264NALUType = 24
+	H264NALUTypeSTAPB         H264NALUType = 25
+	H264NALUTypeMTAP16        H264NALUType = 26
+	H264NALUTypeMTAP24        H264NALUType = 27
+	H264NALUTypeFUA           H264NALUType = 28
+	H264NALUTypeFUB           H264NALUType = 29
 )
 
+// nolint:gochecknoglobals
+var h264NALUTypeNames = map[H264NALUType]string{
+	H264NALUTypeSlice:         "Slice",
+	H264NALUTypeSliceDPA:      "SliceDPA",
+	H264NALUTypeSliceDPB:      "SliceDPB",
+	H264NALUTypeSliceDPC:      "SliceDPC",
+	H264NALUTypeIDR:           "IDR",
+	H264NALUTypeSEI:           "SEI",
+	H264NALUTypeSPS:           "SPS",
+	H264NALUTypePPS:           "PPS",
+	H264NALUTypeAUD:           "AUD",
+	H264NALUTypeEndOfSequence: "EndOfSequence",
+	H264NALUTypeEndOfStream:   "EndOfStream",
+	H264NALUTypeFiller:        "Filler",
+	H264NALUTypeSTAPA:         "STAP-A",
+	H264NALUTypeSTAPB:         "STAP-B",
+	H264NALUTypeMTAP16:        "MTAP16",
+	H264NALUTypeMTAP24:        "MTAP24",
+	H264NALUTypeFUA:           "FU-A",
+	H264NALUTypeFUB:           "FU-B",
+}
+
+// String returns the name of the NAL Unit type.
+func (t H264NALUType) String() string {
+	if name, ok := h264NALUTypeNames[t]; ok {
+		return name
+	}
+
+	if t > fillerNALUType && t < stapaNALUType {
+		return fmt.Sprintf("Reserved(%d)", t)
+	}
+
+	return fmt.Sprintf("Unspecified(%d)", t)
+}
+
 // nolint:gochecknoglobals
 var (
 	naluStartCode       = []byte{0x00, 0x00, 0x01}
 	annexbNALUStartCode = []byte{0x00, 0x00, 0x00, 0x01}
 )
 
-func emitNalus(nals []byte, emit func([]byte)) {
-	start := 0
-	length := len(nals)
+// Payload fragments a H264 packet across one or more byte arrays.
+// If SingleNALUMode is set and a NALU exceeds the MTU, no payloads are returned.
+func (p *H264Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	payloads, _ := p.PayloadErr(mtu, payload)
 
-	for start < length {
-		end := bytes.Index(nals[start:], annexbNALUStartCode)
-		offset := 4
-		if end == -1 {
-			end = bytes.Index(nals[start:], naluStartCode)
-			offset = 3
-		}
-		if end == -1 {
-			emit(nals[start:])
+	return payloads
+}
 
-			break
-		}
+// PayloadErr fragments a H264 packet across one or more byte arrays.
+// It returns an error if SingleNALUMode is set and a NALU exceeds the MTU.
+func (p *H264Payloader) PayloadErr(mtu uint16, payload []byte) ([][]byte, error) {
+	return p.payloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments a H264 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls. If SingleNALUMode is set and
+// a NALU exceeds the MTU, dst is returned unchanged.
+func (p *H264Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads, err := p.payloadAppend(dst, mtu, payload)
+	if err != nil {
+		return dst
+	}
 
-		emit(nals[start : start+end])
+	return payloads
+}
 
-		// next NAL start position
-		start += end + offset
+func (p *H264Payloader) payloadAppend(dst [][]byte, mtu uint16, payload []byte) ([][]byte, error) {
+	payloads := dst
+	if err := p.packetize(mtu, payload, true, appendPackets(&payloads)); err != nil {
+		return nil, err
 	}
+
+	return payloads, nil
 }
 
-// Payload fragments a H264 packet across one or more byte arrays.
-func (p *H264Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:cyclop
-	var payloads [][]byte
+// packetize splits payload into STAP-A, single NALU and FU-A packets. For each
+// packet, in order, it calls next with the packet size and writes the packet
+// into the returned buffer, or only counts it if next returns nil. The buffered
+// SPS and PPS NALUs are only updated if commit is set.
+func (p *H264Payloader) packetize( //nolint:cyclop,gocognit
+	mtu uint16,
+	payload []byte,
+	commit bool,
+	next packetSink,
+) error {
 	if len(payload) == 0 {
-		return payloads
+		return nil
 	}
 
-	emitNalus(payload, func(nalu []byte) {
-		if len(nalu) == 0 {
+	spsNalu, ppsNalu := p.spsNalu, p.ppsNalu
+	var singleNALUErr error
+
+	annexb.EmitNALUs(payload, func(nalu []byte) {
+		if len(nalu) == 0 || singleNALUErr != nil {
 			return
 		}
 
@@ -82,43 +170,54 @@ func (p *H264Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 
 		switch {
 		case naluType == audNALUType || naluType == fillerNALUType:
-			return
+			if !p.KeepAUDAndFiller {
+				return
+			}
 		case naluType == spsNALUType:
-			p.spsNalu = nalu
+			spsNalu = nalu
 
 			return
 		case naluType == ppsNALUType:
-			p.ppsNalu = nalu
+			ppsNalu = nalu
 
 			return
-		case p.spsNalu != nil && p.ppsNalu != nil:
+		case spsNalu != nil && ppsNalu != nil:
 			// Pack current NALU with SPS and PPS as STAP-A
-			spsLen := make([]byte, 2)
-			binary.BigEndian.PutUint16(spsLen, uint16(len(p.spsNalu))) // nolint: gosec // G115
-
-			ppsLen := make([]byte, 2)
-			binary.BigEndian.PutUint16(ppsLen, uint16(len(p.ppsNalu))) // nolint: gosec // G115
-
-			stapANalu := []byte{outputStapAHeader}
-			stapANalu = append(stapANalu, spsLen...)
-			stapANalu = append(stapANalu, p.spsNalu...)
-			stapANalu = append(stapANalu, ppsLen...)
-			stapANalu = append(stapANalu, p.ppsNalu...)
-			if len(stapANalu) <= int(mtu) {
-				out := make([]byte, len(stapANalu))
-				copy(out, stapANalu)
-				payloads = append(payloads, out)
+			stapASize := stapaHeaderSize + stapaNALULengthSize + len(spsNalu) + stapaNALULengthSize + len(ppsNalu)
+			if stapASize <= int(mtu) {
+				if out := next(stapASize); out != nil {
+					// The STAP-A NRI is the maximum NRI of the aggregated NALUs
+					stapARefIdc := spsNalu[0] & naluRefIdcBitmask
+					if ppsRefIdc := ppsNalu[0] & naluRefIdcBitmask; ppsRefIdc > stapARefIdc {
+						stapARefIdc = ppsRefIdc
+					}
+
+					out[0] = stapARefIdc | stapaNALUType
+					offset := stapaHeaderSize
+					binary.BigEndian.PutUint16(out[offset:], uint16(len(spsNalu))) // nolint: gosec // G115
+					offset += stapaNALULengthSize
+					offset += copy(out[offset:], spsNalu)
+					binary.BigEndian.PutUint16(out[offset:], uint16(len(ppsNalu))) // nolint: gosec // G115
+					offset += stapaNALULengthSize
+					copy(out[offset:], ppsNalu)
+				}
 			}
 
-			p.spsNalu = nil
-			p.ppsNalu = nil
+			spsNalu = nil
+			ppsNalu = nil
 		}
 
 		// Single NALU
 		if len(nalu) <= int(mtu) {
-			out := make([]byte, len(nalu))
-			copy(out, nalu)
-			payloads = append(payloads, out)
+			if out := next(len(nalu)); out != nil {
+				copy(out, nalu)
+			}
+
+			return
+		}
+
+		if p.SingleNALUMode {
+			singleNALUErr = fmt.Errorf("%w: %d > %d", errH264NALUExceedsMTU, len(nalu), mtu)
 
 			return
 		}
@@ -148,51 +247,120 @@ func (p *H264Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 
 		for naluRemaining > 0 {
 			currentFragmentSize := minInt(maxFragmentSize, naluRemaining)
-			out := make([]byte, fuaHeaderSize+currentFragmentSize)
-
-			// +---------------+
-			// |0|1|2|3|4|5|6|7|
-			// +-+-+-+-+-+-+-+-+
-			// |F|NRI|  Type   |
-			// +---------------+
-			out[0] = fuaNALUType
-			out[0] |= naluRefIdc
-
-			// +---------------+
-			// |0|1|2|3|4|5|6|7|
-			// +-+-+-+-+-+-+-+-+
-			// |S|E|R|  Type   |
-			// +---------------+
-
-			out[1] = naluType
-			if naluRemaining == naluLength {
-				// Set start bit
-				out[1] |= 1 << 7
-			} else if naluRemaining-currentFragmentSize == 0 {
-				// Set end bit
-				out[1] |= 1 << 6
+			if out := next(fuaHeaderSize + currentFragmentSize); out != nil {
+				// +---------------+
+				// |0|1|2|3|4|5|6|7|
+				// +-+-+-+-+-+-+-+-+
+				// |F|NRI|  Type   |
+				// +---------------+
+				out[0] = fuaNALUType
+				out[0] |= naluRefIdc
+
+				// +---------------+
+				// |0|1|2|3|4|5|6|7|
+				// +-+-+-+-+-+-+-+-+
+				// |S|E|R|  Type   |
+				// +---------------+
+
+				out[1] = naluType
+				if naluRemaining == naluLength {
+					// Set start bit
+					out[1] |= 1 << 7
+				} else if naluRemaining-currentFragmentSize == 0 {
+					// Set end bit
+					out[1] |= 1 << 6
+				}
+
+				copy(out[fuaHeaderSize:], nalu[naluIndex:naluIndex+currentFragmentSize])
 			}
 
-			copy(out[fuaHeaderSize:], nalu[naluIndex:naluIndex+currentFragmentSize])
-			payloads = append(payloads, out)
-
 			naluRemaining -= currentFragmentSize
 			naluIndex += currentFragmentSize
 		}
 	})
 
-	return payloads
+	if commit {
+		p.spsNalu = spsNalu
+		p.ppsNalu = ppsNalu
+	}
+
+	return singleNALUErr
+}
+
+// Reset discards the buffered SPS and PPS NALUs, so that they aren't sent
+// with the next NALU. It should be called when the payloaded stream restarts.
+func (p *H264Payloader) Reset() {
+	p.spsNalu = nil
+	p.ppsNalu = nil
+}
+
+// PayloadArena fragments a H264 packet like Payload, but returns all payloads
+// in a single backing array. Payload i is arena[offsets[i]:offsets[i+1]], so
+// offsets has one more element than the number of payloads.
+func (p *H264Payloader) PayloadArena(mtu uint16, payload []byte) (arena []byte, offsets []int) {
+	// Size the arena with a first pass, then write the packets into it
+	count, size := 0, 0
+	err := p.packetize(mtu, payload, false, func(n int) []byte {
+		count++
+		size += n
+
+		return nil
+	})
+	if err != nil || count == 0 {
+		return nil, nil
+	}
+
+	arena = make([]byte, size)
+	offsets = make([]int, 1, count+1)
+	_ = p.packetize(mtu, payload, true, func(n int) []byte {
+		start := offsets[len(offsets)-1]
+		offsets = append(offsets, start+n)
+
+		return arena[start : start+n : start+n]
+	})
+
+	return arena, offsets
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+// Buffered SPS and PPS NALUs are taken into account but left untouched.
+func (p *H264Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	if err := p.packetize(mtu, payload, false, countPackets(&count)); err != nil {
+		return 0
+	}
+
+	return count
 }
 
 // H264Packet represents the H264 header that is stored in the payload of an RTP Packet.
 type H264Packet struct {
-	IsAVC     bool
-	fuaBuffer []byte
+	IsAVC bool
+	// StripEmulationPrevention removes the emulation prevention bytes from the
+	// output NALUs, leaving their RBSP. The output may then contain start code
+	// sequences, so it is best combined with IsAVC.
+	StripEmulationPrevention bool
+	// StartCodeLength is the length of the start codes preceding the output
+	// NALUs when IsAVC is false, 3 or 4. Any other value, such as the default
+	// zero, means 4.
+	StartCodeLength int
+	// MaxPartialPackets limits the number of FU-A fragments a NALU can span.
+	// When exceeded, the partial NALU is dropped and Unmarshal returns an error.
+	// Zero means no limit.
+	MaxPartialPackets int
+	fuaBuffer         []byte
+
+	fuaSequenceNumber uint16
+	fuaPacketCount    int
 
 	videoDepacketizer
 }
 
 func (p *H264Packet) doPackaging(buf, nalu []byte) []byte {
+	if p.StripEmulationPrevention {
+		nalu = annexb.RemoveEmulationPrevention(nalu)
+	}
+
 	if p.IsAVC {
 		buf = binary.BigEndian.AppendUint32(buf, uint32(len(nalu))) // nolint: gosec // G115 false positive
 		buf = append(buf, nalu...)
@@ -200,12 +368,24 @@ func (p *H264Packet) doPackaging(buf, nalu []byte) []byte {
 		return buf
 	}
 
-	buf = append(buf, annexbNALUStartCode...)
+	if p.StartCodeLength == len(naluStartCode) {
+		buf = append(buf, naluStartCode...)
+	} else {
+		buf = append(buf, annexbNALUStartCode...)
+	}
 	buf = append(buf, nalu...)
 
 	return buf
 }
 
+// Reset discards any partially reassembled FU-A NALU. It should be called
+// when the SSRC of the depacketized stream changes.
+func (p *H264Packet) Reset() {
+	p.fuaBuffer = nil
+	p.fuaSequenceNumber = 0
+	p.fuaPacketCount = 0
+}
+
 // IsDetectedFinalPacketInSequence returns true of the packet passed in has the
 // marker bit set indicated the end of a packet sequence.
 func (p *H264Packet) IsDetectedFinalPacketInSequence(rtpPacketMarketBit bool) bool {
@@ -221,6 +401,31 @@ func (p *H264Packet) Unmarshal(payload []byte) ([]byte, error) {
 	return p.parseBody(payload)
 }
 
+// UnmarshalWithSequenceNumber behaves like Unmarshal, but also uses the RTP
+// sequence number to verify that FU-A fragments are contiguous. If a fragment
+// is missing the partially reassembled NALU is discarded and an error is returned.
+// Without sequence numbers, Unmarshal can only detect a lost start fragment, so
+// reassembly across a lost middle fragment is best-effort.
+func (p *H264Packet) UnmarshalWithSequenceNumber(payload []byte, sequenceNumber uint16) ([]byte, error) {
+	if p.zeroAllocation {
+		return payload, nil
+	}
+
+	lastSequenceNumber := p.fuaSequenceNumber
+	p.fuaSequenceNumber = sequenceNumber
+
+	if p.fuaBuffer != nil && len(payload) >= fuaHeaderSize &&
+		payload[0]&naluTypeBitmask == fuaNALUType && payload[1]&fuStartBitmask == 0 &&
+		sequenceNumber != lastSequenceNumber+1 {
+		p.fuaBuffer = nil
+
+		return nil, fmt.Errorf("%w: expected sequence number %d, got %d",
+			errH264IncompleteFUA, lastSequenceNumber+1, sequenceNumber)
+	}
+
+	return p.parseBody(payload)
+}
+
 func (p *H264Packet) parseBody(payload []byte) ([]byte, error) { //nolint:cyclop
 	if len(payload) == 0 {
 		return nil, fmt.Errorf("%w: %d <=0", errShortPacket, len(payload))
@@ -264,11 +469,23 @@ func (p *H264Packet) parseBody(payload []byte) ([]byte, error) { //nolint:cyclop
 			return nil, errShortPacket
 		}
 
-		if p.fuaBuffer == nil {
+		if payload[1]&fuStartBitmask != 0 {
+			// A new start fragment discards any previously incomplete NALU
 			p.fuaBuffer = []byte{}
+			p.fuaPacketCount = 0
+		} else if p.fuaBuffer == nil {
+			// The start fragment was lost, drop fragments until the next one
+			return []byte{}, nil
 		}
 
 		p.fuaBuffer = append(p.fuaBuffer, payload[fuaHeaderSize:]...)
+		p.fuaPacketCount++
+
+		if p.MaxPartialPackets > 0 && p.fuaPacketCount > p.MaxPartialPackets {
+			p.fuaBuffer = nil
+
+			return nil, fmt.Errorf("%w: more than %d FU-A fragments", ErrPartialFrameTooLong, p.MaxPartialPackets)
+		}
 
 		if payload[1]&fuEndBitmask != 0 {
 			naluRefIdc := payload[0] & naluRefIdcBitmask
@@ -284,7 +501,17 @@ func (p *H264Packet) parseBody(payload []byte) ([]byte, error) { //nolint:cyclop
 		return []byte{}, nil
 	}
 
-	return nil, fmt.Errorf("%w: %d", errUnhandledNALUType, naluType)
+	return nil, fmt.Errorf("%w: %s", errUnhandledNALUType, H264NALUType(naluType))
+}
+
+// NALUType returns the NAL Unit type of a H264 RTP payload, such as STAP-A
+// or FU-A for aggregated and fragmented NAL Units.
+func (p *H264Packet) NALUType(payload []byte) H264NALUType {
+	if len(payload) == 0 {
+		return 0
+	}
+
+	return H264NALUType(payload[0] & naluTypeBitmask)
 }
 
 // H264PartitionHeadChecker checks H264 partition head.
@@ -312,3 +539,60 @@ func (*H264Packet) IsPartitionHead(payload []byte) bool {
 
 	return true
 }
+
+// FragmentType reports whether payload is a FU-A or FU-B fragment and, if so,
+// the type of the fragmented NALU and whether the fragment starts or ends it.
+func (*H264Packet) FragmentType(payload []byte) (naluType uint8, isStart bool, isEnd bool, ok bool) {
+	if len(payload) < fuaHeaderSize {
+		return 0, false, false, false
+	}
+
+	if payload[0]&naluTypeBitmask != fuaNALUType && payload[0]&naluTypeBitmask != fubNALUType {
+		return 0, false, false, false
+	}
+
+	return payload[1] & naluTypeBitmask, payload[1]&fuStartBitmask != 0, payload[1]&fuEndBitmask != 0, true
+}
+
+// ReassembleFUA reassembles a NALU from the complete, ordered set of its FU-A
+// fragments, without the state of a H264Packet. The first fragment must have
+// the start bit set, the last one the end bit, and all of them the same NALU
+// type. The NALU is returned without start code or length prefix.
+func ReassembleFUA(fragments [][]byte) ([]byte, error) {
+	if len(fragments) == 0 {
+		return nil, fmt.Errorf("%w: no fragments", errH264InvalidFUA)
+	}
+
+	size := 1
+	for i, fragment := range fragments {
+		if len(fragment) < fuaHeaderSize {
+			return nil, fmt.Errorf("%w: fragment %d", errShortPacket, i)
+		}
+		if fragment[0]&naluTypeBitmask != fuaNALUType {
+			return nil, fmt.Errorf("%w: fragment %d is %s", errH264InvalidFUA, i, H264NALUType(fragment[0]&naluTypeBitmask))
+		}
+
+		isStart := fragment[1]&fuStartBitmask != 0
+		isEnd := fragment[1]&fuEndBitmask != 0
+		if isStart != (i == 0) {
+			return nil, fmt.Errorf("%w: unexpected start bit %v in fragment %d", errH264InvalidFUA, isStart, i)
+		}
+		if isEnd != (i == len(fragments)-1) {
+			return nil, fmt.Errorf("%w: unexpected end bit %v in fragment %d", errH264InvalidFUA, isEnd, i)
+		}
+		if fragmentType, naluType := fragment[1]&naluTypeBitmask, fragments[0][1]&naluTypeBitmask; fragmentType != naluType {
+			return nil, fmt.Errorf("%w: NALU type %d in fragment %d != %d", errH264InvalidFUA, fragmentType, i, naluType)
+		}
+
+		size += len(fragment) - fuaHeaderSize
+	}
+
+	// The NALU header takes the F and NRI bits of the FU indicator
+	nalu := make([]byte, 1, size)
+	nalu[0] = fragments[0][0]&^naluTypeBitmask | fragments[0][1]&naluTypeBitmask
+	for _, fragment := range fragments {
+		nalu = append(nalu, fragment[fuaHeaderSize:]...)
+	}
+
+	return nalu, nil
+}
@@ -4,7 +4,10 @@
 package codecs
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -238,6 +241,339 @@ func TestH264Packet_Unmarshal(t *testing.T) { //nolint:cyclop
 	}
 }
 
+func TestH264Packet_Unmarshal_LostFUAStart(t *testing.T) {
+	fuaPackets := [][]byte{
+		{0x1c, 0x80, 0x01, 0x02, 0x03},
+		{0x1c, 0x00, 0x04, 0x05, 0x06},
+		{0x1c, 0x40, 0x07, 0x08, 0x09},
+	}
+
+	pkt := H264Packet{}
+
+	// Middle and end fragments without a start must be dropped
+	for _, fragment := range fuaPackets[1:] {
+		res, err := pkt.Unmarshal(fragment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 0 {
+			t.Fatalf("Fragment without start should produce no output, got %v", res)
+		}
+	}
+
+	// A complete NALU after the lost start is reassembled correctly
+	var res []byte
+	for _, fragment := range fuaPackets {
+		out, err := pkt.Unmarshal(fragment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, out...)
+	}
+
+	expected := []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Failed to reassemble NALU after lost start, expected %v, got %v", expected, res)
+	}
+
+	// A new start fragment discards an incomplete NALU
+	if _, err := pkt.Unmarshal(fuaPackets[0]); err != nil {
+		t.Fatal(err)
+	}
+	res = nil
+	for _, fragment := range fuaPackets {
+		out, err := pkt.Unmarshal(fragment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, out...)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Incomplete NALU should be discarded on new start, expected %v, got %v", expected, res)
+	}
+}
+
+func TestH264Packet_UnmarshalWithSequenceNumber(t *testing.T) {
+	fuaPackets := [][]byte{
+		{0x1c, 0x80, 0x01, 0x02, 0x03},
+		{0x1c, 0x00, 0x04, 0x05, 0x06},
+		{0x1c, 0x00, 0x07, 0x08, 0x09},
+		{0x1c, 0x40, 0x10, 0x11, 0x12},
+	}
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09,
+		0x10, 0x11, 0x12,
+	}
+
+	t.Run("Contiguous", func(t *testing.T) {
+		pkt := H264Packet{}
+		var res []byte
+		for i, fragment := range fuaPackets {
+			out, err := pkt.UnmarshalWithSequenceNumber(fragment, uint16(65534+i)) //nolint:gosec
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, out...)
+		}
+		if !reflect.DeepEqual(res, expected) {
+			t.Fatalf("Failed to reassemble NALU, expected %v, got %v", expected, res)
+		}
+	})
+
+	t.Run("LostMiddle", func(t *testing.T) {
+		pkt := H264Packet{}
+		if _, err := pkt.UnmarshalWithSequenceNumber(fuaPackets[0], 10); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pkt.UnmarshalWithSequenceNumber(fuaPackets[1], 11); err != nil {
+			t.Fatal(err)
+		}
+		res, err := pkt.UnmarshalWithSequenceNumber(fuaPackets[3], 13)
+		if !errors.Is(err, errH264IncompleteFUA) {
+			t.Fatalf("Expected %v, got %v", errH264IncompleteFUA, err)
+		}
+		if res != nil {
+			t.Fatalf("Incomplete NALU must not be emitted, got %v", res)
+		}
+
+		// The next complete NALU is reassembled
+		res = nil
+		for i, fragment := range fuaPackets {
+			out, err := pkt.UnmarshalWithSequenceNumber(fragment, uint16(14+i)) //nolint:gosec
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, out...)
+		}
+		if !reflect.DeepEqual(res, expected) {
+			t.Fatalf("Failed to reassemble NALU after loss, expected %v, got %v", expected, res)
+		}
+	})
+
+	t.Run("LostMiddleWithoutSequenceNumber", func(t *testing.T) {
+		// Without sequence numbers the loss can't be detected
+		pkt := H264Packet{}
+		var res []byte
+		for _, i := range []int{0, 1, 3} {
+			out, err := pkt.Unmarshal(fuaPackets[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, out...)
+		}
+		if reflect.DeepEqual(res, expected) || len(res) == 0 {
+			t.Fatalf("Unexpected reassembly result %v", res)
+		}
+	})
+}
+
+func TestH264Packet_Reset(t *testing.T) {
+	pkt := H264Packet{}
+	if _, err := pkt.Unmarshal([]byte{0x1c, 0x80, 0x01, 0x02, 0x03}); err != nil {
+		t.Fatal(err)
+	}
+
+	pkt.Reset()
+
+	res, err := pkt.Unmarshal([]byte{0x1c, 0x40, 0x04, 0x05, 0x06})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Fatalf("Reset should discard the partial FU-A NALU, got %v", res)
+	}
+}
+
+func TestH264Packet_StripEmulationPrevention(t *testing.T) {
+	for name, test := range map[string]struct {
+		payload  []byte
+		expected []byte
+	}{
+		"NoEmulationBytes": {
+			payload:  []byte{0x65, 0x01, 0x00, 0x01, 0x02},
+			expected: []byte{0x65, 0x01, 0x00, 0x01, 0x02},
+		},
+		"EmulationBytes": {
+			payload:  []byte{0x65, 0x00, 0x00, 0x03, 0x01, 0x00, 0x00, 0x03, 0x00, 0x00, 0x03},
+			expected: []byte{0x65, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},
+		},
+		"ConsecutiveEmulationBytes": {
+			payload:  []byte{0x65, 0x00, 0x00, 0x03, 0x00, 0x00, 0x03, 0x00},
+			expected: []byte{0x65, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		"NotEmulationByte": {
+			payload:  []byte{0x65, 0x00, 0x00, 0x03, 0x04, 0x00, 0x03, 0x00},
+			expected: []byte{0x65, 0x00, 0x00, 0x03, 0x04, 0x00, 0x03, 0x00},
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			pkt := H264Packet{IsAVC: true, StripEmulationPrevention: true}
+			res, err := pkt.Unmarshal(test.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := append([]byte{0x00, 0x00, 0x00, byte(len(test.expected))}, test.expected...)
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("Expected %v, got %v", expected, res)
+			}
+		})
+	}
+
+	t.Run("FUA", func(t *testing.T) {
+		pkt := H264Packet{StripEmulationPrevention: true}
+		if _, err := pkt.Unmarshal([]byte{0x7c, 0x85, 0x00, 0x00}); err != nil {
+			t.Fatal(err)
+		}
+		res, err := pkt.Unmarshal([]byte{0x7c, 0x45, 0x03, 0x01})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []byte{0x00, 0x00, 0x00, 0x01, 0x65, 0x00, 0x00, 0x01}
+		if !reflect.DeepEqual(res, expected) {
+			t.Fatalf("Expected %v, got %v", expected, res)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		pkt := H264Packet{}
+		payload := []byte{0x65, 0x00, 0x00, 0x03, 0x01}
+		res, err := pkt.Unmarshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(res, append([]byte{0x00, 0x00, 0x00, 0x01}, payload...)) {
+			t.Fatalf("Emulation prevention bytes should be kept, got %v", res)
+		}
+	})
+}
+
+func TestH264Packet_StartCodeLength(t *testing.T) {
+	singleNALU := []byte{0x65, 0x01, 0x02}
+	stapA := []byte{0x78, 0x00, 0x02, 0x67, 0x01, 0x00, 0x02, 0x68, 0x02}
+
+	for name, test := range map[string]struct {
+		startCodeLength int
+		startCode       []byte
+	}{
+		"Default":   {0, []byte{0x00, 0x00, 0x00, 0x01}},
+		"ThreeByte": {3, []byte{0x00, 0x00, 0x01}},
+		"FourByte":  {4, []byte{0x00, 0x00, 0x00, 0x01}},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			pkt := H264Packet{StartCodeLength: test.startCodeLength}
+
+			res, err := pkt.Unmarshal(singleNALU)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := append(append([]byte{}, test.startCode...), singleNALU...)
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("Single NALU: expected %v, got %v", expected, res)
+			}
+
+			res, err = pkt.Unmarshal(stapA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = append(append([]byte{}, test.startCode...), 0x67, 0x01)
+			expected = append(append(expected, test.startCode...), 0x68, 0x02)
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("STAP-A: expected %v, got %v", expected, res)
+			}
+
+			if _, err = pkt.Unmarshal([]byte{0x7c, 0x85, 0x01}); err != nil {
+				t.Fatal(err)
+			}
+			res, err = pkt.Unmarshal([]byte{0x7c, 0x45, 0x02})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = append(append([]byte{}, test.startCode...), 0x65, 0x01, 0x02)
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("FU-A: expected %v, got %v", expected, res)
+			}
+		})
+	}
+}
+
+func TestH264NALUType(t *testing.T) {
+	pkt := H264Packet{}
+	for name, test := range map[string]struct {
+		payload []byte
+		want    H264NALUType
+		str     string
+	}{
+		"Slice":         {[]byte{0x41, 0x9a}, H264NALUTypeSlice, "Slice"},
+		"IDR":           {[]byte{0x65, 0x88}, H264NALUTypeIDR, "IDR"},
+		"SEI":           {[]byte{0x06, 0x05}, H264NALUTypeSEI, "SEI"},
+		"SPS":           {[]byte{0x67, 0x42}, H264NALUTypeSPS, "SPS"},
+		"PPS":           {[]byte{0x68, 0xce}, H264NALUTypePPS, "PPS"},
+		"AUD":           {[]byte{0x09, 0xf0}, H264NALUTypeAUD, "AUD"},
+		"EndOfSequence": {[]byte{0x0a}, H264NALUTypeEndOfSequence, "EndOfSequence"},
+		"Filler":        {[]byte{0x0c, 0xff}, H264NALUTypeFiller, "Filler"},
+		"STAP-A":        {[]byte{0x78, 0x00}, H264NALUTypeSTAPA, "STAP-A"},
+		"STAP-B":        {[]byte{0x19}, H264NALUTypeSTAPB, "STAP-B"},
+		"MTAP16":        {[]byte{0x1a}, H264NALUTypeMTAP16, "MTAP16"},
+		"FU-A":          {[]byte{0x7c, 0x85}, H264NALUTypeFUA, "FU-A"},
+		"FU-B":          {[]byte{0x1d}, H264NALUTypeFUB, "FU-B"},
+		"Reserved":      {[]byte{0x0d}, 13, "Reserved(13)"},
+		"Unspecified":   {[]byte{0x1e}, 30, "Unspecified(30)"},
+		"Empty":         {nil, 0, "Unspecified(0)"},
+	} {
+		if got := pkt.NALUType(test.payload); got != test.want || got.String() != test.str {
+			t.Errorf("%s: expected %d (%s), got %d (%s)", name, test.want, test.str, got, got)
+		}
+	}
+
+	_, err := pkt.Unmarshal([]byte{0x19, 0x00})
+	if !errors.Is(err, errUnhandledNALUType) || !strings.Contains(err.Error(), "STAP-B") {
+		t.Errorf("Expected unhandled NALU type error naming STAP-B, got %v", err)
+	}
+}
+
+func TestH264Packet_MaxPartialPackets(t *testing.T) {
+	pkt := H264Packet{MaxPartialPackets: 3}
+
+	// A NALU spanning exactly the limit is reassembled
+	for _, payload := range [][]byte{{0x7c, 0x85, 0x01}, {0x7c, 0x05, 0x02}} {
+		if _, err := pkt.Unmarshal(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := pkt.Unmarshal([]byte{0x7c, 0x45, 0x03})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x00, 0x00, 0x00, 0x01, 0x65, 0x01, 0x02, 0x03}; !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Expected %v, got %v", expected, res)
+	}
+
+	// Fragments without an end are dropped once over the limit
+	if _, err = pkt.Unmarshal([]byte{0x7c, 0x85, 0x01}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = pkt.Unmarshal([]byte{0x7c, 0x05, 0x02}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = pkt.Unmarshal([]byte{0x7c, 0x05, 0x02}); !errors.Is(err, ErrPartialFrameTooLong) {
+		t.Fatalf("Expected %v, got %v", ErrPartialFrameTooLong, err)
+	}
+	if pkt.fuaBuffer != nil {
+		t.Fatal("Partial NALU should be dropped")
+	}
+
+	// Remaining fragments of the dropped NALU are ignored
+	if res, err = pkt.Unmarshal([]byte{0x7c, 0x45, 0x03}); err != nil || len(res) != 0 {
+		t.Fatalf("Expected the end fragment to be dropped, got %v, %v", res, err)
+	}
+}
+
 func TestH264IsPartitionHead(t *testing.T) {
 	h264 := H264Packet{}
 
@@ -284,7 +620,7 @@ func TestH264IsPartitionHead(t *testing.T) {
 func TestH264Payloader_Payload_SPS_and_PPS_handling(t *testing.T) {
 	pck := H264Payloader{}
 	expected := [][]byte{
-		{0x78, 0x00, 0x03, 0x07, 0x00, 0x01, 0x00, 0x03, 0x08, 0x02, 0x03},
+		{0x18, 0x00, 0x03, 0x07, 0x00, 0x01, 0x00, 0x03, 0x08, 0x02, 0x03},
 		{0x05, 0x04, 0x05},
 	}
 
@@ -303,3 +639,217 @@ func TestH264Payloader_Payload_SPS_and_PPS_handling(t *testing.T) {
 		t.Fatal("SPS and PPS aren't packed together")
 	}
 }
+
+func TestH264Payloader_Reset(t *testing.T) {
+	pck := H264Payloader{}
+	if res := pck.Payload(1500, []byte{0x07, 0x00, 0x01, 0x00, 0x00, 0x01, 0x08, 0x02, 0x03}); len(res) != 0 {
+		t.Fatal("Generated payload should be empty")
+	}
+
+	pck.Reset()
+
+	expected := [][]byte{{0x05, 0x04, 0x05}}
+	if res := pck.Payload(1500, []byte{0x05, 0x04, 0x05}); !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Stale SPS and PPS must not be sent after Reset, got %v", res)
+	}
+}
+
+func TestH264Payloader_KeepAUDAndFiller(t *testing.T) {
+	aud := []byte{0x09, 0xF0}
+	filler := []byte{0x0C, 0xFF, 0xFF, 0x80}
+	slice := []byte{0x01, 0xAA}
+	stream := []byte{0x00, 0x00, 0x00, 0x01}
+	stream = append(append(stream, aud...), 0x00, 0x00, 0x00, 0x01)
+	stream = append(append(stream, slice...), 0x00, 0x00, 0x00, 0x01)
+	stream = append(stream, filler...)
+
+	if res := (&H264Payloader{}).Payload(1500, stream); !reflect.DeepEqual(res, [][]byte{slice}) {
+		t.Fatalf("AUD and filler should be dropped by default, got %v", res)
+	}
+
+	pck := &H264Payloader{KeepAUDAndFiller: true}
+	expected := [][]byte{aud, slice, filler}
+	if res := pck.Payload(1500, stream); !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Expected %v, got %v", expected, res)
+	}
+	if count := pck.PayloadCount(1500, stream); count != len(expected) {
+		t.Fatalf("Expected PayloadCount %d, got %d", len(expected), count)
+	}
+}
+
+func TestH264Payloader_PayloadArena(t *testing.T) {
+	sps := []byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x1f}
+	pps := []byte{0x00, 0x00, 0x00, 0x01, 0x68, 0xce, 0x3c, 0x80}
+	idr := append([]byte{0x00, 0x00, 0x00, 0x01, 0x65}, bytes.Repeat([]byte{0xAA}, 50)...)
+	frame := append(append(append([]byte{}, sps...), pps...), idr...)
+
+	for _, mtu := range []uint16{5, 20, 1500} {
+		expected := (&H264Payloader{}).Payload(mtu, frame)
+		arena, offsets := (&H264Payloader{}).PayloadArena(mtu, frame)
+
+		if len(offsets) != len(expected)+1 {
+			t.Fatalf("MTU %d: expected %d offsets, got %d", mtu, len(expected)+1, len(offsets))
+		}
+		for i := range expected {
+			if got := arena[offsets[i]:offsets[i+1]]; !bytes.Equal(got, expected[i]) {
+				t.Fatalf("MTU %d: payload %d expected %v, got %v", mtu, i, expected[i], got)
+			}
+		}
+		if offsets[len(offsets)-1] != len(arena) {
+			t.Fatalf("MTU %d: last offset %d does not match arena size %d", mtu, offsets[len(offsets)-1], len(arena))
+		}
+	}
+
+	if arena, offsets := (&H264Payloader{}).PayloadArena(1500, nil); arena != nil || offsets != nil {
+		t.Fatal("Expected no arena for empty input")
+	}
+
+	// Only the arena and the offsets are allocated, whatever the number of payloads
+	pck := &H264Payloader{}
+	if allocs := testing.AllocsPerRun(100, func() {
+		pck.PayloadArena(5, frame)
+	}); allocs != 2 {
+		t.Fatalf("Expected 2 allocations, got %v", allocs)
+	}
+}
+
+func TestH264Payloader_Payload_STAPA_NRI(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		sps, pps []byte
+		header   byte
+	}{
+		"SPS and PPS NRI=3":   {sps: []byte{0x67, 0x00}, pps: []byte{0x68, 0x01}, header: 0x78},
+		"SPS NRI=1 PPS NRI=2": {sps: []byte{0x27, 0x00}, pps: []byte{0x48, 0x01}, header: 0x58},
+		"SPS NRI=2 PPS NRI=0": {sps: []byte{0x47, 0x00}, pps: []byte{0x08, 0x01}, header: 0x58},
+		"SPS and PPS NRI=0":   {sps: []byte{0x07, 0x00}, pps: []byte{0x08, 0x01}, header: 0x18},
+	} {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			pck := H264Payloader{}
+			pck.Payload(1500, testCase.sps)
+			pck.Payload(1500, testCase.pps)
+
+			res := pck.Payload(1500, []byte{0x65, 0x02})
+			if len(res) != 2 {
+				t.Fatalf("Generated %d payloads instead of 2", len(res))
+			}
+			if res[0][0] != testCase.header {
+				t.Errorf("STAP-A header expected to be %#x, got %#x", testCase.header, res[0][0])
+			}
+			if res[0][0]&0x80 != 0 || res[0][0]&naluTypeBitmask != stapaNALUType {
+				t.Errorf("STAP-A header must have F=0 and type=24, got %#x", res[0][0])
+			}
+		})
+	}
+}
+
+func TestH264Payloader_SingleNALUMode(t *testing.T) {
+	small := []byte{0x00, 0x00, 0x01, 0x01, 0xAA, 0xBB}
+	large := append([]byte{0x00, 0x00, 0x01, 0x05}, make([]byte, 100)...)
+
+	pck := &H264Payloader{SingleNALUMode: true}
+	res, err := pck.PayloadErr(50, small)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res, [][]byte{{0x01, 0xAA, 0xBB}}) {
+		t.Fatalf("Unexpected payloads: %v", res)
+	}
+
+	res, err = pck.PayloadErr(50, append(append([]byte{}, small...), large...))
+	if !errors.Is(err, errH264NALUExceedsMTU) {
+		t.Fatalf("Expected %v, got %v", errH264NALUExceedsMTU, err)
+	}
+	if res != nil {
+		t.Fatal("Generated payload should be nil on error")
+	}
+	if res = pck.Payload(50, large); len(res) != 0 {
+		t.Fatal("Payload should not fragment when SingleNALUMode is set")
+	}
+	if count := pck.PayloadCount(50, large); count != 0 {
+		t.Fatalf("PayloadCount returned %d instead of 0", count)
+	}
+
+	res, err = pck.PayloadErr(200, large)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("Generated %d payloads instead of 1", len(res))
+	}
+
+	pck = &H264Payloader{}
+	res, err = pck.PayloadErr(50, large)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("Generated %d payloads instead of 3", len(res))
+	}
+}
+
+func TestReassembleFUA(t *testing.T) {
+	nalu, err := ReassembleFUA([][]byte{
+		{0x7c, 0x85, 0x01, 0x02},
+		{0x7c, 0x05, 0x03},
+		{0x7c, 0x45, 0x04},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x65, 0x01, 0x02, 0x03, 0x04}; !reflect.DeepEqual(nalu, expected) {
+		t.Fatalf("Expected %v, got %v", expected, nalu)
+	}
+
+	// Fragments produced by the payloader are reassembled into the original NALU
+	original := append([]byte{0x65}, bytes.Repeat([]byte{0xAA, 0xBB}, 100)...)
+	pck := H264Payloader{}
+	if nalu, err = ReassembleFUA(pck.Payload(50, original)); err != nil || !reflect.DeepEqual(nalu, original) {
+		t.Fatalf("Expected %v, got %v, %v", original, nalu, err)
+	}
+
+	for name, test := range map[string]struct {
+		fragments [][]byte
+		err       error
+	}{
+		"NoFragments": {
+			fragments: nil,
+			err:       errH264InvalidFUA,
+		},
+		"ShortFragment": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x7c}},
+			err:       errShortPacket,
+		},
+		"NotFUA": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x65, 0x45, 0x02}},
+			err:       errH264InvalidFUA,
+		},
+		"MissingStart": {
+			fragments: [][]byte{{0x7c, 0x05, 0x01}, {0x7c, 0x45, 0x02}},
+			err:       errH264InvalidFUA,
+		},
+		"MissingEnd": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x7c, 0x05, 0x02}},
+			err:       errH264InvalidFUA,
+		},
+		"StartInTheMiddle": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x7c, 0x85, 0x02}, {0x7c, 0x45, 0x03}},
+			err:       errH264InvalidFUA,
+		},
+		"EndInTheMiddle": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x7c, 0x45, 0x02}, {0x7c, 0x45, 0x03}},
+			err:       errH264InvalidFUA,
+		},
+		"TypeMismatch": {
+			fragments: [][]byte{{0x7c, 0x85, 0x01}, {0x7c, 0x41, 0x02}},
+			err:       errH264InvalidFUA,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReassembleFUA(test.fragments); !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import (
+	"errors"
+	"io"
+
+	"github.com/pion/rtp/codecs/internal/annexb"
+)
+
+// h264StreamReadSize is the size of the reads done by PayloadReader.
+const h264StreamReadSize = 4096
+
+// PayloadReader reads an Annex B H264 stream from r and payloads each NALU as
+// soon as the start code of the next one is read, so the stream doesn't need to
+// be held in memory. emit is called with every payload, in order, and an error
+// returned by emit stops PayloadReader and is returned as is. The last NALU is
+// payloaded when r returns io.EOF. Start codes may be split across reads.
+func (p *H264Payloader) PayloadReader(mtu uint16, r io.Reader, emit func(payload []byte) error) error {
+	var payloads [][]byte
+	var splitter annexb.Splitter
+	chunk := make([]byte, h264StreamReadSize)
+
+	emitNALU := func(nalu []byte) error {
+		if len(nalu) == 0 {
+			return nil
+		}
+
+		// SPS and PPS NALUs are kept by the payloader, so they must not
+		// reference the splitter buffer, which is overwritten by the next reads
+		if naluType := nalu[0] & naluTypeBitmask; naluType == spsNALUType || naluType == ppsNALUType {
+			nalu = append([]byte{}, nalu...)
+		}
+
+		var err error
+		payloads, err = p.payloadAppend(payloads[:0], mtu, nalu)
+		if err != nil {
+			return err
+		}
+		for _, payload := range payloads {
+			if err := emit(payload); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for {
+		n, readErr := r.Read(chunk)
+		if err := splitter.Write(chunk[:n], emitNALU); err != nil {
+			return err
+		}
+
+		switch {
+		case errors.Is(readErr, io.EOF):
+			return splitter.Flush(emitNALU)
+		case readErr != nil:
+			return readErr
+		}
+	}
+}
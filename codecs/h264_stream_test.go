@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// chunkReader returns the data in reads of at most size bytes.
+type chunkReader struct {
+	data []byte
+	size int
+}
+
+func (r *chunkReader) Read(buf []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(buf[:minInt(len(buf), r.size)], r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestH264Payloader_PayloadReader(t *testing.T) {
+	largeNALU := bytes.Repeat([]byte{0x65, 0xAA, 0x00, 0xBB}, 300)
+	nalus := [][]byte{
+		{0x09, 0xF0},
+		{0x67, 0x42, 0x00, 0x1F},
+		{0x68, 0xCE, 0x3C, 0x80},
+		largeNALU,
+		{0x41, 0x9A, 0x00, 0x00, 0x03, 0x01},
+		{0x01, 0x02},
+	}
+	// Mix 4-byte and 3-byte start codes
+	stream := []byte{}
+	for i, nalu := range nalus {
+		if i%2 == 0 {
+			stream = append(stream, annexbNALUStartCode...)
+		} else {
+			stream = append(stream, naluStartCode...)
+		}
+		stream = append(stream, nalu...)
+	}
+
+	const mtu = 100
+	expected := [][]byte{}
+	expectedPayloader := &H264Payloader{}
+	for _, nalu := range nalus {
+		expected = append(expected, expectedPayloader.Payload(mtu, nalu)...)
+	}
+
+	for _, size := range []int{1, 2, 3, 5, 64, len(stream)} {
+		payloader := &H264Payloader{}
+		actual := [][]byte{}
+		err := payloader.PayloadReader(mtu, &chunkReader{data: stream, size: size}, func(payload []byte) error {
+			actual = append(actual, payload)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("read size %d: %v", size, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("read size %d: payloads differ from Payload: got %d payloads, want %d", size, len(actual), len(expected))
+		}
+	}
+
+	errEmit := errors.New("emit")
+	err := (&H264Payloader{}).PayloadReader(mtu, bytes.NewReader(stream), func([]byte) error {
+		return errEmit
+	})
+	if !errors.Is(err, errEmit) {
+		t.Fatalf("expected %v, got %v", errEmit, err)
+	}
+
+	errRead := errors.New("read")
+	err = (&H264Payloader{}).PayloadReader(mtu, io.MultiReader(bytes.NewReader([]byte{0x00, 0x00, 0x01, 0x01}),
+		&errReader{err: errRead}), func([]byte) error {
+		return nil
+	})
+	if !errors.Is(err, errRead) {
+		t.Fatalf("expected %v, got %v", errRead, err)
+	}
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
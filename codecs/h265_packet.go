@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+
+	"github.com/pion/rtp/codecs/internal/annexb"
 )
 
 //
@@ -15,8 +17,10 @@ import (
 //
 
 var (
-	errH265CorruptedPacket   = errors.New("corrupted h265 packet")
-	errInvalidH265PacketType = errors.New("invalid h265 packet type")
+	errH265CorruptedPacket       = errors.New("corrupted h265 packet")
+	errInvalidH265PacketType     = errors.New("invalid h265 packet type")
+	errH265FragmentationDisabled = errors.New("h265 NALU exceeds MTU and fragmentation is disabled")
+	errH265DONGap                = errors.New("h265 fragmentation unit does not continue a started NALU")
 )
 
 //
@@ -32,6 +36,12 @@ const (
 	h265NaluFragmentationUnitType = 49
 	// https://datatracker.ietf.org/doc/html/rfc7798#section-4.4.4
 	h265NaluPACIPacketType = 50
+
+	// NAL unit types below this value are VCL NAL units (H.265 Table 7-1).
+	h265NaluFirstNonVCLType = 32
+	h265NaluVPSType         = 32
+	h265NaluSPSType         = 33
+	h265NaluPPSType         = 34
 )
 
 // H265NALUHeader is a H265 NAL Unit Header.
@@ -101,6 +111,97 @@ func (h H265NALUHeader) IsPACIPacket() bool {
 	return h.Type() == h265NaluPACIPacketType
 }
 
+// TypeName returns the name of the NAL Unit type.
+func (h H265NALUHeader) TypeName() string {
+	return H265NALUType(h.Type()).String()
+}
+
+// H265NALUType is the type of a H265 NAL Unit, as defined in H.265 Table 7-1
+// and RFC 7798 for the RTP payload specific types.
+type H265NALUType uint8
+
+// H265 NAL Unit types.
+const (
+	H265NALUTypeTrailN    H265NALUType = 0
+	H265NALUTypeTrailR    H265NALUType = 1
+	H265NALUTypeTSAN      H265NALUType = 2
+	H265NALUTypeTSAR      H265NALUType = 3
+	H265NALUTypeSTSAN     H265NALUType = 4
+	H265NALUTypeSTSAR     H265NALUType = 5
+	H265NALUTypeRADLN     H265NALUType = 6
+	H265NALUTypeRADLR     H265NALUType = 7
+	H265NALUTypeRASLN     H265NALUType = 8
+	H265NALUTypeRASLR     H265NALUType = 9
+	H265NALUTypeBLAWLP    H265NALUType = 16
+	H265NALUTypeBLAWRADL  H265NALUType = 17
+	H265NALUTypeBLANLP    H265NALUType = 18
+	H265NALUTypeIDRWRADL  H265NALUType = 19
+	H265NALUTypeIDRNLP    H265NALUType = 20
+	H265NALUTypeCRA       H265NALUType = 21
+	H265NALUTypeVPS       H265NALUType = 32
+	H265NALUTypeSPS       H265NALUType = 33
+	H265NALUTypePPS       H265NALUType = 34
+	H265NALUTypeAUD       H265NALUType = 35
+	H265NALUTypeEOS       H265NALUType = 36
+	H265NALUTypeEOB       H265NALUType = 37
+	H265NALUTypeFD        H265NALUType = 38
+	H265NALUTypePrefixSEI H265NALUType = 39
+	H265NALUTypeSuffixSEI H265NALUType = 40
+	H265NALUTypeAP        H265NALUType = 48
+	H265NALUTypeFU        H265NALUType = 49
+	H265NALUTypePACI      H265NALUType = 50
+)
+
+// NAL unit types from 41 to this value are reserved non-VCL NAL units.
+const h265NALUTypeLastReserved H265NALUType = 47
+
+var h265NALUTypeNames = map[H265NALUType]string{ //nolint:gochecknoglobals
+	H265NALUTypeTrailN:    "TRAIL_N",
+	H265NALUTypeTrailR:    "TRAIL_R",
+	H265NALUTypeTSAN:      "TSA_N",
+	H265NALUTypeTSAR:      "TSA_R",
+	H265NALUTypeSTSAN:     "STSA_N",
+	H265NALUTypeSTSAR:     "STSA_R",
+	H265NALUTypeRADLN:     "RADL_N",
+	H265NALUTypeRADLR:     "RADL_R",
+	H265NALUTypeRASLN:     "RASL_N",
+	H265NALUTypeRASLR:     "RASL_R",
+	H265NALUTypeBLAWLP:    "BLA_W_LP",
+	H265NALUTypeBLAWRADL:  "BLA_W_RADL",
+	H265NALUTypeBLANLP:    "BLA_N_LP",
+	H265NALUTypeIDRWRADL:  "IDR_W_RADL",
+	H265NALUTypeIDRNLP:    "IDR_N_LP",
+	H265NALUTypeCRA:       "CRA_NUT",
+	H265NALUTypeVPS:       "VPS",
+	H265NALUTypeSPS:       "SPS",
+	H265NALUTypePPS:       "PPS",
+	H265NALUTypeAUD:       "AUD",
+	H265NALUTypeEOS:       "EOS",
+	H265NALUTypeEOB:       "EOB",
+	H265NALUTypeFD:        "FD",
+	H265NALUTypePrefixSEI: "PREFIX_SEI",
+	H265NALUTypeSuffixSEI: "SUFFIX_SEI",
+	H265NALUTypeAP:        "AP",
+	H265NALUTypeFU:        "FU",
+	H265NALUTypePACI:      "PACI",
+}
+
+// String returns the name of the NAL Unit type.
+func (t H265NALUType) String() string {
+	if name, ok := h265NALUTypeNames[t]; ok {
+		return name
+	}
+
+	switch {
+	case t < h265NaluFirstNonVCLType:
+		return fmt.Sprintf("RSV_VCL(%d)", t)
+	case t <= h265NALUTypeLastReserved:
+		return fmt.Sprintf("RSV_NVCL(%d)", t)
+	default:
+		return fmt.Sprintf("UNSPEC(%d)", t)
+	}
+}
+
 //
 // Single NAL Unit Packet implementation
 //
@@ -290,7 +391,8 @@ type H265AggregationPacket struct {
 	firstUnit  *H265AggregationUnitFirst
 	otherUnits []H265AggregationUnit
 
-	mightNeedDONL bool
+	mightNeedDONL   bool
+	allowSingleUnit bool
 }
 
 // WithDONL can be called to specify whether or not DONL might be parsed.
@@ -299,6 +401,12 @@ func (p *H265AggregationPacket) WithDONL(value bool) {
 	p.mightNeedDONL = value
 }
 
+// WithSingleUnit can be called to accept Aggregation Packets carrying a single
+// Aggregation Unit. RFC 7798 requires at least two, but some senders emit only one.
+func (p *H265AggregationPacket) WithSingleUnit(value bool) {
+	p.allowSingleUnit = value
+}
+
 // Unmarshal parses the passed byte slice and stores the result in the H265AggregationPacket this method is called upon.
 func (p *H265AggregationPacket) Unmarshal(payload []byte) ([]byte, error) { //nolint:cyclop
 	// sizeof(headers)
@@ -377,7 +485,7 @@ func (p *H265AggregationPacket) Unmarshal(payload []byte) ([]byte, error) { //no
 	}
 
 	// There need to be **at least** two Aggregation Units (first + another one)
-	if len(units) == 0 {
+	if len(units) == 0 && !p.allowSingleUnit {
 		return nil, errShortPacket
 	}
 
@@ -642,7 +750,7 @@ func (p *H265PACIPacket) TSCI() *H265TSCI {
 		return nil
 	}
 
-	tsci := H265TSCI((uint32(p.phes[0]) << 16) | (uint32(p.phes[1]) << 8) | uint32(p.phes[0]))
+	tsci := H265TSCI((uint32(p.phes[0]) << 24) | (uint32(p.phes[1]) << 16) | (uint32(p.phes[2]) << 8))
 
 	return &tsci
 }
@@ -759,19 +867,40 @@ var (
 //
 
 // H265Packet represents a H265 packet, stored in the payload of an RTP packet.
+// Unlike H264Packet, it has no MaxPartialPackets limit: fragmentation units are
+// returned as they are received and never buffered, so a lost end fragment
+// can't make a partial NALU grow.
 type H265Packet struct {
-	packet        isH265Packet
-	mightNeedDONL bool
+	packet          isH265Packet
+	payloadHeader   H265NALUHeader
+	mightNeedDONL   bool
+	allowSingleUnit bool
+
+	// fragmentDON and fragmentType describe the fragmented NALU being
+	// received when DONL is enabled, nil when no fragmented NALU is in progress.
+	fragmentDON  *uint16
+	fragmentType uint8
 
 	videoDepacketizer
 }
 
 // WithDONL can be called to specify whether or not DONL might be parsed.
 // DONL may need to be parsed if `sprop-max-don-diff` is greater than 0 on the RTP stream.
+//
+// When DONL is enabled, fragmentation units are also checked for continuity:
+// only the first fragment of a NALU carries the DONL, which is then reported by
+// DONL() for every following fragment of the same NALU. A fragment that does not
+// continue a started NALU, for example because its start was lost, is an error.
 func (p *H265Packet) WithDONL(value bool) {
 	p.mightNeedDONL = value
 }
 
+// WithSingleUnit can be called to accept Aggregation Packets carrying a single
+// Aggregation Unit. RFC 7798 requires at least two, but some senders emit only one.
+func (p *H265Packet) WithSingleUnit(value bool) {
+	p.allowSingleUnit = value
+}
+
 // Unmarshal parses the passed byte slice and stores the result in the H265Packet this method is called upon.
 func (p *H265Packet) Unmarshal(payload []byte) ([]byte, error) { // nolint:cyclop
 	if payload == nil {
@@ -802,11 +931,18 @@ func (p *H265Packet) Unmarshal(payload []byte) ([]byte, error) { // nolint:cyclo
 			return nil, err
 		}
 
+		if p.mightNeedDONL {
+			if err := p.trackFragmentDON(decoded); err != nil {
+				return nil, err
+			}
+		}
+
 		p.packet = decoded
 
 	case payloadHeader.IsAggregationPacket():
 		decoded := &H265AggregationPacket{}
 		decoded.WithDONL(p.mightNeedDONL)
+		decoded.WithSingleUnit(p.allowSingleUnit)
 
 		if _, err := decoded.Unmarshal(payload); err != nil {
 			return nil, err
@@ -825,9 +961,59 @@ func (p *H265Packet) Unmarshal(payload []byte) ([]byte, error) { // nolint:cyclo
 		p.packet = decoded
 	}
 
+	p.payloadHeader = payloadHeader
+
 	return nil, nil
 }
 
+// TemporalID returns the TemporalId of the last parsed packet, read from the
+// TID field of its payload header, which holds TemporalId plus one. For
+// Aggregation and PACI packets it is the lowest TemporalId of the contained
+// NALUs. It returns false if no packet has been parsed or the TID is zero.
+func (p *H265Packet) TemporalID() (uint8, bool) {
+	if p.packet == nil || p.payloadHeader.TID() == 0 {
+		return 0, false
+	}
+
+	return p.payloadHeader.TID() - 1, true
+}
+
+// trackFragmentDON checks that fu continues the fragmented NALU in progress
+// and assigns it the DON carried by the first fragment.
+func (p *H265Packet) trackFragmentDON(fu *H265FragmentationUnitPacket) error {
+	fuHeader := fu.FuHeader()
+
+	if fuHeader.S() {
+		p.fragmentDON = fu.donl
+		p.fragmentType = fuHeader.FuType()
+	} else {
+		if p.fragmentDON == nil {
+			return fmt.Errorf("%w: missing start fragment", errH265DONGap)
+		}
+		if p.fragmentType != fuHeader.FuType() {
+			p.fragmentDON = nil
+
+			return fmt.Errorf("%w: type %d != %d", errH265DONGap, fuHeader.FuType(), p.fragmentType)
+		}
+
+		don := *p.fragmentDON
+		fu.donl = &don
+	}
+
+	if fuHeader.E() {
+		p.fragmentDON = nil
+	}
+
+	return nil
+}
+
+// Reset discards the previously parsed packet. It should be called
+// when the SSRC of the depacketized stream changes.
+func (p *H265Packet) Reset() {
+	p.packet = nil
+	p.fragmentDON = nil
+}
+
 // Packet returns the populated packet.
 // Must be casted to one of:
 // - *H265SingleNALUnitPacket
@@ -852,21 +1038,128 @@ func (*H265Packet) IsPartitionHead(payload []byte) bool {
 	return true
 }
 
+// FragmentType reports whether payload is a Fragmentation Unit and, if so, the
+// type of the fragmented NALU and whether the fragment starts or ends it.
+func (*H265Packet) FragmentType(payload []byte) (naluType uint8, isStart bool, isEnd bool, ok bool) {
+	if len(payload) < h265NaluHeaderSize+h265FragmentationUnitHeaderSize ||
+		H265NALUHeader(binary.BigEndian.Uint16(payload[0:2])).Type() != h265NaluFragmentationUnitType {
+		return 0, false, false, false
+	}
+
+	fuHeader := H265FragmentationUnitHeader(payload[2])
+
+	return fuHeader.FuType(), fuHeader.S(), fuHeader.E(), true
+}
+
 // H265Payloader payloads H265 packets.
 type H265Payloader struct {
 	AddDONL         bool
 	SkipAggregation bool
-	donl            uint16
+	// ErrorOnFragmentation makes PayloadErr return an error instead of
+	// emitting Fragmentation Units when a NALU does not fit in the MTU.
+	ErrorOnFragmentation bool
+	// AggregateParameterSets holds back VPS, SPS and PPS NALUs, along with any
+	// non-VCL NALUs following them, and sends them in a single Aggregation
+	// Packet with the next VCL NALU, even across calls to Payload. When they
+	// don't fit in the MTU together, they are payloaded as usual.
+	AggregateParameterSets bool
+	donl                   uint16
+	pendingNALUs           [][]byte
+}
+
+func isH265ParameterSet(nalu []byte) bool {
+	naluType := newH265NALUHeader(nalu[0], nalu[1]).Type()
+
+	return naluType == h265NaluVPSType || naluType == h265NaluSPSType || naluType == h265NaluPPSType
+}
+
+// holdNALU reports whether nalu should be added to the NALUs waiting to be
+// aggregated with the next VCL NALU.
+func (p *H265Payloader) holdNALU(pending [][]byte, nalu []byte) bool {
+	if !p.AggregateParameterSets {
+		return false
+	}
+	if isH265ParameterSet(nalu) {
+		return true
+	}
+
+	return len(pending) != 0 && newH265NALUHeader(nalu[0], nalu[1]).Type() >= h265NaluFirstNonVCLType
+}
+
+// aggregationPacketSize returns the size of an Aggregation Packet containing nalus.
+func (p *H265Payloader) aggregationPacketSize(nalus [][]byte) int {
+	size := h265NaluHeaderSize
+	for i, nalu := range nalus {
+		size += 2 + len(nalu)
+		if p.AddDONL {
+			if i == 0 {
+				size += 2
+			} else {
+				size++
+			}
+		}
+	}
+
+	return size
 }
 
 // Payload fragments a H265 packet across one or more byte arrays.
-func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:gocognit,cyclop
-	var payloads [][]byte
+// If ErrorOnFragmentation is set and a NALU exceeds the MTU, no payloads are returned.
+func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	payloads, _ := p.PayloadErr(mtu, payload)
+
+	return payloads
+}
+
+// PayloadErr fragments a H265 packet across one or more byte arrays.
+// It returns an error if ErrorOnFragmentation is set and a NALU exceeds the MTU.
+func (p *H265Payloader) PayloadErr(mtu uint16, payload []byte) ([][]byte, error) {
+	return p.payloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments a H265 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls. If ErrorOnFragmentation is
+// set and a NALU exceeds the MTU, dst is returned unchanged.
+func (p *H265Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads, err := p.payloadAppend(dst, mtu, payload)
+	if err != nil {
+		return dst
+	}
+
+	return payloads
+}
+
+func (p *H265Payloader) payloadAppend(dst [][]byte, mtu uint16, payload []byte) ([][]byte, error) {
+	payloads := dst
+	if err := p.packetize(mtu, payload, true, appendPackets(&payloads)); err != nil {
+		return nil, err
+	}
+
+	return payloads, nil
+}
+
+// packetize splits payload into single NALU, aggregation and fragmentation unit
+// packets. For each packet, in order, it calls next with the packet size and
+// writes the packet into the returned buffer, or only counts it if next returns
+// nil. The DONL and the held back NALUs are only updated if commit is set.
+func (p *H265Payloader) packetize( //nolint:gocognit,cyclop
+	mtu uint16,
+	payload []byte,
+	commit bool,
+	next packetSink,
+) error {
 	if len(payload) == 0 || mtu == 0 {
-		return payloads
+		return nil
 	}
 
-	bufferedNALUs := make([][]byte, 0)
+	var fragmentationErr error
+
+	donl := p.donl
+	pendingNALUs := p.pendingNALUs[:len(p.pendingNALUs):len(p.pendingNALUs)]
+
+	// Most aggregation packets hold a few NALUs, which then need no allocation
+	var bufferedStorage [8][]byte
+	bufferedNALUs := bufferedStorage[:0]
 	aggregationBufferSize := 0
 
 	flushBufferedNals := func() {
@@ -878,29 +1171,24 @@ func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 			nalu := bufferedNALUs[0]
 
 			if p.AddDONL {
-				buf := make([]byte, len(nalu)+2)
-
-				// copy the NALU header to the payload header
-				copy(buf[0:h265NaluHeaderSize], nalu[0:h265NaluHeaderSize])
-
-				// copy the DONL into the header
-				binary.BigEndian.PutUint16(buf[h265NaluHeaderSize:h265NaluHeaderSize+2], p.donl)
+				if out := next(len(nalu) + 2); out != nil {
+					// copy the NALU header to the payload header
+					copy(out[0:h265NaluHeaderSize], nalu[0:h265NaluHeaderSize])
 
-				// write the payload
-				copy(buf[h265NaluHeaderSize+2:], nalu[h265NaluHeaderSize:])
+					// copy the DONL into the header
+					binary.BigEndian.PutUint16(out[h265NaluHeaderSize:h265NaluHeaderSize+2], donl)
 
-				p.donl++
+					// write the payload
+					copy(out[h265NaluHeaderSize+2:], nalu[h265NaluHeaderSize:])
+				}
 
-				payloads = append(payloads, buf)
-			} else {
+				donl++
+			} else if out := next(len(nalu)); out != nil {
 				// write the nalu directly to the payload
-				payloads = append(payloads, nalu)
+				copy(out, nalu)
 			}
-		} else {
+		} else if out := next(aggregationBufferSize); out != nil {
 			// construct an aggregation packet
-			aggregationPacketSize := aggregationBufferSize
-			buf := make([]byte, aggregationPacketSize)
-
 			layerID := uint8(math.MaxUint8)
 			tid := uint8(math.MaxUint8)
 			for _, nalu := range bufferedNALUs {
@@ -915,30 +1203,29 @@ func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 				}
 			}
 
-			binary.BigEndian.PutUint16(buf[0:2], (uint16(h265NaluAggregationPacketType)<<9)|(uint16(layerID)<<3)|uint16(tid))
+			binary.BigEndian.PutUint16(out[0:2], (uint16(h265NaluAggregationPacketType)<<9)|(uint16(layerID)<<3)|uint16(tid))
 
 			index := 2
 			for i, nalu := range bufferedNALUs {
 				if p.AddDONL {
 					if i == 0 {
-						binary.BigEndian.PutUint16(buf[index:index+2], p.donl)
+						binary.BigEndian.PutUint16(out[index:index+2], donl)
 						index += 2
 					} else {
-						buf[index] = byte(i - 1)
+						out[index] = byte(i - 1)
 						index++
 					}
 				}
 
 				// Since the type of mtu is uint16, len(nalu) fits in as well, so it is safe.
 				// #nosec
-				binary.BigEndian.PutUint16(buf[index:index+2], uint16(len(nalu)))
+				binary.BigEndian.PutUint16(out[index:index+2], uint16(len(nalu)))
 				index += 2
-				index += copy(buf[index:], nalu)
+				index += copy(out[index:], nalu)
 			}
-			payloads = append(payloads, buf)
 		}
 		// clear the buffered NALUs
-		bufferedNALUs = make([][]byte, 0)
+		bufferedNALUs = bufferedNALUs[:0]
 		aggregationBufferSize = 0
 	}
 
@@ -958,12 +1245,7 @@ func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 		return marginalAggregationSize
 	}
 
-	emitNalus(payload, func(nalu []byte) {
-		if len(nalu) < 2 {
-			// NALU header is 2 bytes
-			return
-		}
-
+	payloadNALU := func(nalu []byte) {
 		naluLen := len(nalu) + 2
 		if p.AddDONL {
 			naluLen += 2
@@ -984,6 +1266,12 @@ func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 				flushBufferedNals()
 			}
 		} else {
+			if p.ErrorOnFragmentation {
+				fragmentationErr = fmt.Errorf("%w: %d > %d", errH265FragmentationDisabled, naluLen, mtu)
+
+				return
+			}
+
 			// if this nalu doesn't fit in the current mtu, it needs to be fragmented
 			fuPacketHeaderSize := h265FragmentationUnitHeaderSize + 2 /* payload header size */
 			if p.AddDONL {
@@ -1012,45 +1300,129 @@ func (p *H265Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:
 					curentFUPayloadSize = maxFUPayloadSize
 				}
 
-				out := make([]byte, fuPacketHeaderSize+curentFUPayloadSize)
+				if out := next(fuPacketHeaderSize + curentFUPayloadSize); out != nil {
+					// write the payload header
+					binary.BigEndian.PutUint16(out[0:2], uint16(naluHeader))
+					out[0] = (out[0] & 0b10000001) | h265NaluFragmentationUnitType<<1
+
+					// write the fragment header
+					out[2] = byte(H265FragmentationUnitHeader(naluHeader.Type()))
+					if len(nalu) == fullNALUSize {
+						// Set start bit
+						out[2] |= 1 << 7
+					} else if len(nalu)-curentFUPayloadSize == 0 {
+						// Set end bit
+						out[2] |= 1 << 6
+					}
 
-				// write the payload header
-				binary.BigEndian.PutUint16(out[0:2], uint16(naluHeader))
-				out[0] = (out[0] & 0b10000001) | h265NaluFragmentationUnitType<<1
+					if p.AddDONL {
+						// write the DONL header
+						binary.BigEndian.PutUint16(out[3:5], donl)
 
-				// write the fragment header
-				out[2] = byte(H265FragmentationUnitHeader(naluHeader.Type()))
-				if len(nalu) == fullNALUSize {
-					// Set start bit
-					out[2] |= 1 << 7
-				} else if len(nalu)-curentFUPayloadSize == 0 {
-					// Set end bit
-					out[2] |= 1 << 6
+						// copy the fragment payload
+						copy(out[5:], nalu[0:curentFUPayloadSize])
+					} else {
+						// copy the fragment payload
+						copy(out[3:], nalu[0:curentFUPayloadSize])
+					}
 				}
 
 				if p.AddDONL {
-					// write the DONL header
-					binary.BigEndian.PutUint16(out[3:5], p.donl)
-
-					p.donl++
-
-					// copy the fragment payload
-					copy(out[5:], nalu[0:curentFUPayloadSize])
-				} else {
-					// copy the fragment payload
-					copy(out[3:], nalu[0:curentFUPayloadSize])
+					donl++
 				}
 
-				// append the fragment to the payload
-				payloads = append(payloads, out)
-
 				// advance the nalu data pointer
 				nalu = nalu[curentFUPayloadSize:]
 			}
 		}
+	}
+
+	annexb.EmitNALUs(payload, func(nalu []byte) {
+		if len(nalu) < 2 || fragmentationErr != nil {
+			// NALU header is 2 bytes
+			return
+		}
+
+		if p.holdNALU(pendingNALUs, nalu) {
+			pendingNALUs = append(pendingNALUs, nalu)
+
+			return
+		}
+		if len(pendingNALUs) == 0 {
+			payloadNALU(nalu)
+
+			return
+		}
+
+		nalus := append(pendingNALUs, nalu)
+		pendingNALUs = nil
+		if size := p.aggregationPacketSize(nalus); size <= int(mtu) {
+			// emit the held back NALUs and this one as a single Aggregation Packet
+			flushBufferedNals()
+			bufferedNALUs = append(bufferedNALUs, nalus...)
+			aggregationBufferSize = size
+			flushBufferedNals()
+
+			return
+		}
+
+		for _, n := range nalus {
+			payloadNALU(n)
+		}
 	})
 
-	flushBufferedNals()
+	if fragmentationErr == nil {
+		flushBufferedNals()
+	}
 
-	return payloads
+	if commit {
+		p.donl = donl
+		p.pendingNALUs = pendingNALUs
+	}
+
+	return fragmentationErr
+}
+
+// H265PayloadInfo describes a payload produced by the H265Payloader together
+// with whether it ends the access unit.
+type H265PayloadInfo struct {
+	Data []byte
+	// Marker is set on the last payload of an access unit, whose RTP packet
+	// should have the marker bit set (RFC 7798 Section 4.1).
+	Marker bool
+}
+
+// PayloadWithInfo fragments a H265 access unit like Payload, and also reports
+// which payload ends the access unit. Payloads containing no VCL NALU, such as
+// parameter sets sent ahead of a picture, don't end the access unit.
+func (p *H265Payloader) PayloadWithInfo(mtu uint16, payload []byte) []H265PayloadInfo {
+	payloads := p.Payload(mtu, payload)
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	hasVCLNALU := false
+	annexb.EmitNALUs(payload, func(nalu []byte) {
+		if len(nalu) >= h265NaluHeaderSize && newH265NALUHeader(nalu[0], nalu[1]).IsTypeVCLUnit() {
+			hasVCLNALU = true
+		}
+	})
+
+	infos := make([]H265PayloadInfo, len(payloads))
+	for i, out := range payloads {
+		infos[i] = H265PayloadInfo{Data: out}
+	}
+	infos[len(infos)-1].Marker = hasVCLNALU
+
+	return infos
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *H265Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	if err := p.packetize(mtu, payload, false, countPackets(&count)); err != nil {
+		return 0
+	}
+
+	return count
 }
@@ -4,6 +4,7 @@
 package codecs
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -105,6 +106,36 @@ func TestH265_NALU_Header(t *testing.T) {
 	}
 }
 
+func TestH265_NALU_TypeName(t *testing.T) {
+	for _, test := range []struct {
+		RawHeader []byte
+		Name      string
+	}{
+		{[]byte{0x00, 0x01}, "TRAIL_N"},
+		{[]byte{0x02, 0x01}, "TRAIL_R"},
+		{[]byte{0x26, 0x01}, "IDR_W_RADL"},
+		{[]byte{0x28, 0x01}, "IDR_N_LP"},
+		{[]byte{0x2a, 0x01}, "CRA_NUT"},
+		{[]byte{0x40, 0x01}, "VPS"},
+		{[]byte{0x42, 0x01}, "SPS"},
+		{[]byte{0x44, 0x01}, "PPS"},
+		{[]byte{0x46, 0x01}, "AUD"},
+		{[]byte{0x4e, 0x01}, "PREFIX_SEI"},
+		{[]byte{0x50, 0x01}, "SUFFIX_SEI"},
+		{[]byte{0x60, 0x01}, "AP"},
+		{[]byte{0x62, 0x01}, "FU"},
+		{[]byte{0x64, 0x01}, "PACI"},
+		{[]byte{0x14, 0x01}, "RSV_VCL(10)"},
+		{[]byte{0x52, 0x01}, "RSV_NVCL(41)"},
+		{[]byte{0x7e, 0x01}, "UNSPEC(63)"},
+	} {
+		header := newH265NALUHeader(test.RawHeader[0], test.RawHeader[1])
+		if name := header.TypeName(); name != test.Name {
+			t.Errorf("Type %d: expected name %s, got %s", header.Type(), test.Name, name)
+		}
+	}
+}
+
 func TestH265_FU_Header(t *testing.T) {
 	tt := [...]struct {
 		header H265FragmentationUnitHeader
@@ -436,6 +467,44 @@ func TestH265_AggregationPacket(t *testing.T) { //nolint:cyclop
 	}
 }
 
+func TestH265_AggregationPacket_SingleUnit(t *testing.T) {
+	raw := []byte{0x60, 0x01, 0x00, 0x03, 0x02, 0x01, 0xaa}
+
+	strict := &H265AggregationPacket{}
+	if _, err := strict.Unmarshal(raw); !errors.Is(err, errShortPacket) {
+		t.Fatalf("Expected %v, got %v", errShortPacket, err)
+	}
+
+	lenient := &H265AggregationPacket{}
+	lenient.WithSingleUnit(true)
+	if _, err := lenient.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lenient.FirstUnit().NalUnit(), []byte{0x02, 0x01, 0xaa}) {
+		t.Fatalf("Unexpected first unit %v", lenient.FirstUnit().NalUnit())
+	}
+	if len(lenient.OtherUnits()) != 0 {
+		t.Fatalf("Expected no other units, got %d", len(lenient.OtherUnits()))
+	}
+
+	// A truncated first unit is still an error
+	if _, err := lenient.Unmarshal(raw[:len(raw)-1]); !errors.Is(err, errShortPacket) {
+		t.Fatalf("Expected %v, got %v", errShortPacket, err)
+	}
+
+	pck := &H265Packet{}
+	if _, err := pck.Unmarshal(raw); !errors.Is(err, errShortPacket) {
+		t.Fatalf("Expected %v, got %v", errShortPacket, err)
+	}
+	pck.WithSingleUnit(true)
+	if _, err := pck.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pck.Packet().(*H265AggregationPacket); !ok {
+		t.Fatal("Expected H265AggregationPacket")
+	}
+}
+
 func TestH265_FragmentationUnitPacket(t *testing.T) { //nolint:cyclop
 	tt := [...]struct {
 		Raw         []byte
@@ -538,6 +607,42 @@ func TestH265_FragmentationUnitPacket(t *testing.T) { //nolint:cyclop
 	}
 }
 
+func TestH265PACIPacket_TSCI(t *testing.T) {
+	// TL0PICIDX=0xAA, IrapPicID=0xBB, S=1, E=0, RES=0x15
+	raw := []byte{0x64, 0x01, 0x64, 0b00111000, 0xAA, 0xBB, 0x95, 0xAB, 0xCD, 0xEF}
+
+	packet := &H265PACIPacket{}
+	if _, err := packet.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	tsci := packet.TSCI()
+	if tsci == nil {
+		t.Fatal("Expected TSCI to be present")
+	}
+	if tsci.TL0PICIDX() != 0xAA {
+		t.Errorf("Expected TL0PICIDX 0xAA, got %#x", tsci.TL0PICIDX())
+	}
+	if tsci.IrapPicID() != 0xBB {
+		t.Errorf("Expected IrapPicID 0xBB, got %#x", tsci.IrapPicID())
+	}
+	if !tsci.S() || tsci.E() {
+		t.Errorf("Expected S=1 and E=0, got S=%v and E=%v", tsci.S(), tsci.E())
+	}
+	if tsci.RES() != 0x15 {
+		t.Errorf("Expected RES 0x15, got %#x", tsci.RES())
+	}
+
+	// F0 is not set
+	raw[3] = 0b00110000
+	if _, err := packet.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if packet.TSCI() != nil {
+		t.Error("Expected no TSCI without F0")
+	}
+}
+
 func TestH265_TemporalScalabilityControlInformation(t *testing.T) {
 	tt := [...]struct {
 		Value             H265TSCI
@@ -816,6 +921,156 @@ func TestH265_Packet(t *testing.T) {
 	}
 }
 
+func TestH265_Packet_Reset(t *testing.T) {
+	pck := &H265Packet{}
+	if _, err := pck.Unmarshal([]byte{0x62, 0x01, 0x93, 0xaf, 0xaf, 0xaf, 0xaf}); err != nil {
+		t.Fatal(err)
+	}
+	if pck.Packet() == nil {
+		t.Fatal("Packet should be populated after Unmarshal")
+	}
+
+	pck.Reset()
+
+	if pck.Packet() != nil {
+		t.Fatal("Reset should discard the parsed packet")
+	}
+}
+
+func TestH265_Packet_DONContinuity(t *testing.T) {
+	start := []byte{0x62, 0x01, 0x93, 0x12, 0x34, 0xaa}
+	middle := []byte{0x62, 0x01, 0x13, 0xbb}
+	end := []byte{0x62, 0x01, 0x53, 0xcc}
+	otherType := []byte{0x62, 0x01, 0x14, 0xbb}
+
+	t.Run("Continuous", func(t *testing.T) {
+		pck := &H265Packet{}
+		pck.WithDONL(true)
+
+		for _, raw := range [][]byte{start, middle, end} {
+			if _, err := pck.Unmarshal(raw); err != nil {
+				t.Fatal(err)
+			}
+
+			fu, ok := pck.Packet().(*H265FragmentationUnitPacket)
+			if !ok {
+				t.Fatal("Expected H265FragmentationUnitPacket")
+			}
+			if fu.DONL() == nil || *fu.DONL() != 0x1234 {
+				t.Fatalf("Expected DONL 0x1234, got %v", fu.DONL())
+			}
+		}
+	})
+
+	t.Run("MissingStart", func(t *testing.T) {
+		pck := &H265Packet{}
+		pck.WithDONL(true)
+
+		if _, err := pck.Unmarshal(middle); !errors.Is(err, errH265DONGap) {
+			t.Fatalf("Expected errH265DONGap, got %v", err)
+		}
+	})
+
+	t.Run("AfterEnd", func(t *testing.T) {
+		pck := &H265Packet{}
+		pck.WithDONL(true)
+
+		for _, raw := range [][]byte{start, end} {
+			if _, err := pck.Unmarshal(raw); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := pck.Unmarshal(middle); !errors.Is(err, errH265DONGap) {
+			t.Fatalf("Expected errH265DONGap, got %v", err)
+		}
+	})
+
+	t.Run("TypeMismatch", func(t *testing.T) {
+		pck := &H265Packet{}
+		pck.WithDONL(true)
+
+		if _, err := pck.Unmarshal(start); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pck.Unmarshal(otherType); !errors.Is(err, errH265DONGap) {
+			t.Fatalf("Expected errH265DONGap, got %v", err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		pck := &H265Packet{}
+		pck.WithDONL(true)
+
+		if _, err := pck.Unmarshal(start); err != nil {
+			t.Fatal(err)
+		}
+		pck.Reset()
+		if _, err := pck.Unmarshal(middle); !errors.Is(err, errH265DONGap) {
+			t.Fatalf("Expected errH265DONGap, got %v", err)
+		}
+	})
+
+	t.Run("WithoutDONL", func(t *testing.T) {
+		pck := &H265Packet{}
+		if _, err := pck.Unmarshal(middle); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestH265_Packet_TemporalID(t *testing.T) {
+	pck := &H265Packet{}
+	if _, ok := pck.TemporalID(); ok {
+		t.Fatal("TemporalID must not be reported before parsing a packet")
+	}
+
+	for name, test := range map[string]struct {
+		payload    []byte
+		temporalID uint8
+		ok         bool
+	}{
+		"SingleNALUnit": {
+			payload:    []byte{0x02, 0x03, 0xAA},
+			temporalID: 2,
+			ok:         true,
+		},
+		"AggregationPacket": {
+			payload:    []byte{0x60, 0x01, 0x00, 0x03, 0x02, 0x01, 0xAA, 0x00, 0x03, 0x02, 0x02, 0xBB},
+			temporalID: 0,
+			ok:         true,
+		},
+		"FragmentationUnit": {
+			payload:    []byte{0x62, 0x02, 0x81, 0xAA},
+			temporalID: 1,
+			ok:         true,
+		},
+		"ZeroTID": {
+			payload: []byte{0x02, 0x00, 0xAA},
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			pck := &H265Packet{}
+			if _, err := pck.Unmarshal(test.payload); err != nil {
+				t.Fatal(err)
+			}
+
+			temporalID, ok := pck.TemporalID()
+			if temporalID != test.temporalID || ok != test.ok {
+				t.Fatalf("Expected TemporalID (%d, %v), got (%d, %v)", test.temporalID, test.ok, temporalID, ok)
+			}
+		})
+	}
+
+	if _, err := pck.Unmarshal([]byte{0x02, 0x03, 0xAA}); err != nil {
+		t.Fatal(err)
+	}
+	pck.Reset()
+	if _, ok := pck.TemporalID(); ok {
+		t.Fatal("TemporalID must not be reported after Reset")
+	}
+}
+
 func TestH265IsPartitionHead(t *testing.T) {
 	h265 := H265Packet{}
 
@@ -1159,6 +1414,157 @@ func TestH265Payloader_Real(t *testing.T) {
 	}
 }
 
+func TestH265Payloader_ErrorOnFragmentation(t *testing.T) {
+	nalu := append([]byte{0x00, 0x00, 0x01, 0x02, 0x01}, make([]byte, 100)...)
+
+	pck := H265Payloader{ErrorOnFragmentation: true}
+	res, err := pck.PayloadErr(50, nalu)
+	if !errors.Is(err, errH265FragmentationDisabled) {
+		t.Fatalf("Expected %v, got %v", errH265FragmentationDisabled, err)
+	}
+	if res != nil {
+		t.Fatal("Generated payload should be nil on error")
+	}
+	if res = pck.Payload(50, nalu); len(res) != 0 {
+		t.Fatal("Payload should not fragment when ErrorOnFragmentation is set")
+	}
+
+	res, err = pck.PayloadErr(200, nalu)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("Generated %d payloads instead of 1", len(res))
+	}
+
+	pck = H265Payloader{}
+	res, err = pck.PayloadErr(50, nalu)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("Generated %d payloads instead of 3", len(res))
+	}
+}
+
+func TestH265Payloader_AggregateParameterSets(t *testing.T) {
+	vps := []byte{0x40, 0x01, 0x0c, 0x01}
+	sps := []byte{0x42, 0x01, 0x01, 0x01}
+	pps := []byte{0x44, 0x01, 0xc1, 0x72}
+	idr := []byte{0x26, 0x01, 0xaf, 0x09}
+	expected := [][]byte{{
+		0x60, 0x01,
+		0x00, 0x04, 0x40, 0x01, 0x0c, 0x01,
+		0x00, 0x04, 0x42, 0x01, 0x01, 0x01,
+		0x00, 0x04, 0x44, 0x01, 0xc1, 0x72,
+		0x00, 0x04, 0x26, 0x01, 0xaf, 0x09,
+	}}
+
+	t.Run("SeparateCalls", func(t *testing.T) {
+		pck := H265Payloader{AggregateParameterSets: true}
+		for _, nalu := range [][]byte{vps, sps, pps} {
+			if res := pck.Payload(1200, nalu); len(res) != 0 {
+				t.Fatalf("Parameter set should be held back, got %v", res)
+			}
+		}
+		if res := pck.Payload(1200, idr); !reflect.DeepEqual(res, expected) {
+			t.Fatalf("Expected %v, got %v", expected, res)
+		}
+		if res := pck.Payload(1200, idr); !reflect.DeepEqual(res, [][]byte{idr}) {
+			t.Fatalf("Expected single NALU packet, got %v", res)
+		}
+	})
+
+	t.Run("SkipAggregation", func(t *testing.T) {
+		pck := H265Payloader{AggregateParameterSets: true, SkipAggregation: true}
+		stream := []byte{}
+		for _, nalu := range [][]byte{vps, sps, pps, idr} {
+			stream = append(stream, 0x00, 0x00, 0x00, 0x01)
+			stream = append(stream, nalu...)
+		}
+		if res := pck.Payload(1200, stream); !reflect.DeepEqual(res, expected) {
+			t.Fatalf("Expected %v, got %v", expected, res)
+		}
+	})
+
+	t.Run("ExceedsMTU", func(t *testing.T) {
+		pck := H265Payloader{AggregateParameterSets: true}
+		largeIDR := append([]byte{0x26, 0x01}, make([]byte, 40)...)
+		pck.Payload(30, vps)
+		pck.Payload(30, sps)
+		pck.Payload(30, pps)
+		res := pck.Payload(30, largeIDR)
+		if len(res) != 3 {
+			t.Fatalf("Generated %d payloads instead of 3", len(res))
+		}
+		if !reflect.DeepEqual(res[0], expected[0][:20]) {
+			t.Fatalf("Expected parameter sets aggregation packet %v, got %v", expected[0][:20], res[0])
+		}
+		for _, fu := range res[1:] {
+			if naluType := newH265NALUHeader(fu[0], fu[1]).Type(); naluType != h265NaluFragmentationUnitType {
+				t.Fatalf("Expected Fragmentation Unit, got NALU type %d", naluType)
+			}
+		}
+	})
+}
+
+func TestH265Payloader_PayloadWithInfo(t *testing.T) {
+	parameterSets := []byte{
+		0x00, 0x00, 0x00, 0x01, 0x40, 0x01, 0x0c, 0x01,
+		0x00, 0x00, 0x00, 0x01, 0x42, 0x01, 0x01, 0x01,
+		0x00, 0x00, 0x00, 0x01, 0x44, 0x01, 0xc1, 0x72,
+	}
+	idr := append([]byte{0x00, 0x00, 0x00, 0x01, 0x26, 0x01}, make([]byte, 100)...)
+
+	t.Run("Aggregated", func(t *testing.T) {
+		pck := H265Payloader{}
+		infos := pck.PayloadWithInfo(1200, append(append([]byte{}, parameterSets...), idr...))
+		if len(infos) != 1 {
+			t.Fatalf("Generated %d payloads instead of 1", len(infos))
+		}
+		if !newH265NALUHeader(infos[0].Data[0], infos[0].Data[1]).IsAggregationPacket() {
+			t.Fatal("Expected an aggregation packet")
+		}
+		if !infos[0].Marker {
+			t.Fatal("Aggregation packet ending the access unit should have the marker")
+		}
+	})
+
+	t.Run("Fragmented", func(t *testing.T) {
+		pck := H265Payloader{}
+		infos := pck.PayloadWithInfo(50, idr)
+		if len(infos) != 3 {
+			t.Fatalf("Generated %d payloads instead of 3", len(infos))
+		}
+		for i, info := range infos {
+			if !newH265NALUHeader(info.Data[0], info.Data[1]).IsFragmentationUnit() {
+				t.Fatalf("Payload %d: expected a fragmentation unit", i)
+			}
+			if info.Marker != (i == len(infos)-1) {
+				t.Errorf("Payload %d: unexpected marker %v", i, info.Marker)
+			}
+		}
+		if !reflect.DeepEqual(infos[0].Data, (&H265Payloader{}).Payload(50, idr)[0]) {
+			t.Error("Payload data should match Payload")
+		}
+	})
+
+	t.Run("ParameterSetsOnly", func(t *testing.T) {
+		pck := H265Payloader{}
+		infos := pck.PayloadWithInfo(1200, parameterSets)
+		if len(infos) != 1 || infos[0].Marker {
+			t.Fatalf("Parameter sets alone should not end the access unit, got %v", infos)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		pck := H265Payloader{}
+		if infos := pck.PayloadWithInfo(1200, nil); infos != nil {
+			t.Fatalf("Expected no payloads, got %v", infos)
+		}
+	})
+}
+
 func uint8ptr(v uint8) *uint8 {
 	return &v
 }
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+// Package annexb implements helpers for H264 and H265 Annex B byte streams and
+// their NAL units, shared by the codecs packages.
+package annexb
+
+import "bytes"
+
+// nolint:gochecknoglobals
+var startCode = []byte{0x00, 0x00, 0x01}
+
+// EmitNALUs calls emit with each NAL unit of an Annex B byte stream, in order,
+// without its start code. The stream is split at the earliest start code,
+// whether 3 or 4 bytes long. The bytes preceding the first start code are
+// emitted too, as an empty NAL unit when the stream starts with a start code.
+func EmitNALUs(stream []byte, emit func(nalu []byte)) {
+	start := 0
+	for start < len(stream) {
+		end, next, ok := nextNALU(stream, start, start)
+		if !ok {
+			emit(stream[start:])
+
+			break
+		}
+
+		emit(stream[start:end])
+		start = next
+	}
+}
+
+// Splitter splits an Annex B byte stream read in chunks into NAL units, like
+// EmitNALUs, emitting each of them as soon as the start code following it is
+// read. Start codes may be split across chunks. The zero value is ready to use.
+type Splitter struct {
+	buf []byte
+	// start of the current NAL unit in buf, and where to search its end from
+	start, search int
+}
+
+// Write appends data to the stream and calls emit with each NAL unit it
+// completes. The NAL units are only valid until the next call. An error
+// returned by emit stops Write and is returned as is.
+func (s *Splitter) Write(data []byte, emit func(nalu []byte) error) error {
+	// Drop the NAL units emitted by the previous call
+	s.buf = append(s.buf[:0], s.buf[s.start:]...)
+	s.search -= s.start
+	s.start = 0
+	s.buf = append(s.buf, data...)
+
+	for {
+		end, next, ok := nextNALU(s.buf, s.start, s.search)
+		if !ok {
+			break
+		}
+
+		if err := emit(s.buf[s.start:end]); err != nil {
+			return err
+		}
+		s.start, s.search = next, next
+	}
+
+	// Search again the end of buf, which may hold the beginning of a start code
+	s.search = len(s.buf) - (len(startCode) - 1)
+	if s.search < s.start {
+		s.search = s.start
+	}
+
+	return nil
+}
+
+// Flush calls emit with the last NAL unit of the stream, which no start code
+// ends, and resets the Splitter for a new stream.
+func (s *Splitter) Flush(emit func(nalu []byte) error) error {
+	nalu := s.buf[s.start:]
+	s.buf, s.start, s.search = s.buf[:0], 0, 0
+
+	return emit(nalu)
+}
+
+// nextNALU returns the end of the NAL unit starting at stream[start:] and the
+// start of the following one, looking for the start code between them from
+// search. ok is false if there is no start code.
+func nextNALU(stream []byte, start, search int) (end, next int, ok bool) {
+	index := bytes.Index(stream[search:], startCode)
+	if index == -1 {
+		return 0, 0, false
+	}
+
+	end = search + index
+	next = end + len(startCode)
+	// The zero byte of a 4-byte start code doesn't belong to the NAL unit
+	if end > start && stream[end-1] == 0x00 {
+		end--
+	}
+
+	return end, next, true
+}
+
+// RemoveEmulationPrevention returns a copy of nalu without the 0x03 bytes
+// inserted after two zero bytes to prevent start code emulation. A 0x03 is
+// only an emulation prevention byte when followed by a byte no greater than
+// 0x03 or by the end of the NALU, other occurrences are left untouched.
+func RemoveEmulationPrevention(nalu []byte) []byte {
+	out := make([]byte, 0, len(nalu))
+	zeros := 0
+	for i, b := range nalu {
+		if zeros >= 2 && b == 0x03 && (i == len(nalu)-1 || nalu[i+1] <= 0x03) {
+			zeros = 0
+
+			continue
+		}
+
+		if b == 0x00 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, b)
+	}
+
+	return out
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package annexb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEmitNALUs(t *testing.T) {
+	for name, test := range map[string]struct {
+		stream   []byte
+		expected [][]byte
+	}{
+		"FourByteStartCodes": {
+			stream:   []byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x00, 0x00, 0x01, 0x68, 0xce},
+			expected: [][]byte{{}, {0x67, 0x42}, {0x68, 0xce}},
+		},
+		"ThreeByteThenFourByte": {
+			stream:   []byte{0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x00, 0x00, 0x01, 0x68, 0xce},
+			expected: [][]byte{{}, {0x67, 0x42}, {0x68, 0xce}},
+		},
+		"FourByteThenThreeByte": {
+			stream:   []byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x00, 0x01, 0x68, 0xce},
+			expected: [][]byte{{}, {0x67, 0x42}, {0x68, 0xce}},
+		},
+		"NoStartCode": {
+			stream:   []byte{0x65, 0x88},
+			expected: [][]byte{{0x65, 0x88}},
+		},
+		"Empty": {
+			stream: nil,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var nalus [][]byte
+			EmitNALUs(test.stream, func(nalu []byte) {
+				nalus = append(nalus, nalu)
+			})
+			if !reflect.DeepEqual(nalus, test.expected) {
+				t.Fatalf("Expected %v, got %v", test.expected, nalus)
+			}
+		})
+	}
+}
+
+func TestSplitter(t *testing.T) {
+	stream := []byte{
+		0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x1f,
+		0x00, 0x00, 0x01, 0x68, 0xce,
+		0x00, 0x00, 0x00, 0x01, 0x65, 0x88, 0x84, 0x00,
+		0x00, 0x00, 0x01, 0x41,
+	}
+
+	var expected [][]byte
+	EmitNALUs(stream, func(nalu []byte) {
+		expected = append(expected, nalu)
+	})
+
+	// Start codes split across writes are found like in a single stream
+	var splitter Splitter
+	for size := 1; size <= len(stream); size++ {
+		var nalus [][]byte
+		emit := func(nalu []byte) error {
+			nalus = append(nalus, append([]byte{}, nalu...))
+
+			return nil
+		}
+
+		for offset := 0; offset < len(stream); offset += size {
+			if err := splitter.Write(stream[offset:minInt(offset+size, len(stream))], emit); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := splitter.Flush(emit); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(nalus, expected) {
+			t.Fatalf("write size %d: expected %v, got %v", size, expected, nalus)
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func TestRemoveEmulationPrevention(t *testing.T) {
+	for name, test := range map[string]struct {
+		nalu     []byte
+		expected []byte
+	}{
+		"NoEmulationBytes": {
+			nalu:     []byte{0x65, 0x01, 0x00, 0x01, 0x02},
+			expected: []byte{0x65, 0x01, 0x00, 0x01, 0x02},
+		},
+		"EmulationBytes": {
+			nalu:     []byte{0x65, 0x00, 0x00, 0x03, 0x01, 0x00, 0x00, 0x03, 0x00, 0x00, 0x03},
+			expected: []byte{0x65, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},
+		},
+		"NotEmulationByte": {
+			nalu:     []byte{0x65, 0x00, 0x00, 0x03, 0x04},
+			expected: []byte{0x65, 0x00, 0x00, 0x03, 0x04},
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if res := RemoveEmulationPrevention(test.nalu); !bytes.Equal(res, test.expected) {
+				t.Fatalf("Expected %v, got %v", test.expected, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "strings"
+
+// MIME types understood by IsPartitionHead and ClockRate, in lower case.
+const (
+	mimeTypeH264 = "video/h264"
+	mimeTypeH265 = "video/h265"
+	mimeTypeVP8  = "video/vp8"
+	mimeTypeVP9  = "video/vp9"
+	mimeTypeAV1  = "video/av1"
+	mimeTypeOpus = "audio/opus"
+	mimeTypePCMU = "audio/pcmu"
+	mimeTypePCMA = "audio/pcma"
+	mimeTypeG722 = "audio/g722"
+)
+
+const (
+	videoClockRate = 90000
+	opusClockRate  = 48000
+	// G.722 uses an 8000 Hz RTP clock despite its 16000 Hz sampling rate, see RFC 3551
+	g7xxClockRate = 8000
+)
+
+// ClockRate returns the RTP clock rate of the codec with the given MIME type,
+// such as 90000 for "video/VP8". MIME types are matched case-insensitively,
+// and false is returned for unsupported ones.
+func ClockRate(mimeType string) (uint32, bool) {
+	switch strings.ToLower(mimeType) {
+	case mimeTypeH264, mimeTypeH265, mimeTypeVP8, mimeTypeVP9, mimeTypeAV1:
+		return videoClockRate, true
+	case mimeTypeOpus:
+		return opusClockRate, true
+	case mimeTypePCMU, mimeTypePCMA, mimeTypeG722:
+		return g7xxClockRate, true
+	default:
+		return 0, false
+	}
+}
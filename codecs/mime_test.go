@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "testing"
+
+func TestClockRate(t *testing.T) {
+	for mimeType, expected := range map[string]uint32{
+		"video/H264": 90000,
+		"video/H265": 90000,
+		"video/VP8":  90000,
+		"video/VP9":  90000,
+		"video/AV1":  90000,
+		"audio/opus": 48000,
+		"audio/PCMU": 8000,
+		"audio/PCMA": 8000,
+		"audio/G722": 8000,
+		"VIDEO/vp8":  90000,
+	} {
+		clockRate, ok := ClockRate(mimeType)
+		if !ok || clockRate != expected {
+			t.Errorf("%s: expected %d, got %d (%v)", mimeType, expected, clockRate, ok)
+		}
+	}
+
+	if clockRate, ok := ClockRate("video/unknown"); ok || clockRate != 0 {
+		t.Errorf("Expected unsupported MIME type, got %d (%v)", clockRate, ok)
+	}
+}
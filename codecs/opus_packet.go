@@ -4,18 +4,54 @@
 package codecs
 
 // OpusPayloader payloads Opus packets.
-type OpusPayloader struct{}
+type OpusPayloader struct {
+	// StartOfTalkspurt makes the next payload the first of a talkspurt, after a
+	// period of silence. It is cleared once that payload is produced.
+	StartOfTalkspurt bool
+
+	talkspurtStart bool
+}
 
 // Payload fragments an Opus packet across one or more byte arrays.
-func (p *OpusPayloader) Payload(_ uint16, payload []byte) [][]byte {
+func (p *OpusPayloader) Payload(mtu uint16, payload []byte) [][]byte {
 	if payload == nil {
 		return [][]byte{}
 	}
 
+	return p.PayloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend packetizes an Opus packet like Payload and appends the payload to dst.
+// Passing dst[:0] reuses the outer slice across calls.
+func (p *OpusPayloader) PayloadAppend(dst [][]byte, _ uint16, payload []byte) [][]byte {
+	if payload == nil {
+		return dst
+	}
+
+	p.talkspurtStart = p.StartOfTalkspurt
+	p.StartOfTalkspurt = false
+
 	out := make([]byte, len(payload))
 	copy(out, payload)
 
-	return [][]byte{out}
+	return append(dst, out)
+}
+
+// Marker reports whether the payload at index of the payloads last produced
+// by Payload starts a talkspurt, and should have the RTP marker bit set as
+// required by RFC 7587. It can be passed to the EnableMarker method of a
+// packetizer.
+func (p *OpusPayloader) Marker(_ [][]byte, index int) bool {
+	return p.talkspurtStart && index == 0
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *OpusPayloader) PayloadCount(_ uint16, payload []byte) int {
+	if payload == nil {
+		return 0
+	}
+
+	return 1
 }
 
 // OpusPacket represents the Opus header that is stored in the payload of an RTP Packet.
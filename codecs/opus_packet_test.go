@@ -62,6 +62,29 @@ func TestOpusPayloader_Payload(t *testing.T) {
 	}
 }
 
+func TestOpusPayloader_StartOfTalkspurt(t *testing.T) {
+	pck := OpusPayloader{}
+	payload := []byte{0x90, 0x90, 0x90}
+
+	for i, startOfTalkspurt := range []bool{false, true, false, false, true} {
+		pck.StartOfTalkspurt = startOfTalkspurt
+		res := pck.Payload(100, payload)
+		if pck.Marker(res, 0) != startOfTalkspurt {
+			t.Errorf("Payload %d: expected marker %v", i, startOfTalkspurt)
+		}
+		if pck.StartOfTalkspurt {
+			t.Errorf("Payload %d: StartOfTalkspurt should be cleared", i)
+		}
+	}
+
+	// A nil payload doesn't consume the start of the talkspurt
+	pck.StartOfTalkspurt = true
+	pck.Payload(100, nil)
+	if !pck.StartOfTalkspurt {
+		t.Fatal("StartOfTalkspurt should be kept until a payload is produced")
+	}
+}
+
 func TestOpusIsPartitionHead(t *testing.T) {
 	opus := &OpusPacket{}
 	t.Run("NormalPacket", func(t *testing.T) {
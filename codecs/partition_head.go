@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "strings"
+
+// IsPartitionHead checks whether the payload is the head of a partition for
+// the codec with the given MIME type, such as "video/VP8". MIME types are
+// matched case-insensitively, and false is returned for unsupported ones.
+func IsPartitionHead(mimeType string, payload []byte) bool {
+	switch strings.ToLower(mimeType) {
+	case mimeTypeH264:
+		return (&H264Packet{}).IsPartitionHead(payload)
+	case mimeTypeH265:
+		return (&H265Packet{}).IsPartitionHead(payload)
+	case mimeTypeVP8:
+		return (&VP8Packet{}).IsPartitionHead(payload)
+	case mimeTypeVP9:
+		return (&VP9Packet{}).IsPartitionHead(payload)
+	case mimeTypeAV1:
+		// The first OBU element doesn't continue an OBU from the previous packet
+		return len(payload) > 0 && payload[0]&zMask == 0
+	case mimeTypeOpus, mimeTypePCMU, mimeTypePCMA, mimeTypeG722:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import "testing"
+
+func TestIsPartitionHead(t *testing.T) {
+	for name, test := range map[string]struct {
+		mimeType string
+		payload  []byte
+		expected bool
+	}{
+		"H264Single":      {"video/H264", []byte{0x01, 0xAA}, true},
+		"H264FUAStart":    {"video/H264", []byte{0x1C, 0x85}, true},
+		"H264FUAMiddle":   {"video/H264", []byte{0x1C, 0x05}, false},
+		"H265Single":      {"video/H265", []byte{0x02, 0x01, 0xAA}, true},
+		"H265FUStart":     {"video/H265", []byte{0x62, 0x01, 0x93}, true},
+		"H265FUMiddle":    {"video/H265", []byte{0x62, 0x01, 0x13}, false},
+		"VP8Start":        {"video/VP8", []byte{0x10, 0xAA}, true},
+		"VP8Continuation": {"video/VP8", []byte{0x00, 0xAA}, false},
+		"VP9Start":        {"video/VP9", []byte{0x08, 0xAA}, true},
+		"VP9Continuation": {"video/VP9", []byte{0x00, 0xAA}, false},
+		"AV1Start":        {"video/AV1", []byte{0x10, 0xAA}, true},
+		"AV1Continuation": {"video/AV1", []byte{0x90, 0xAA}, false},
+		"AV1Empty":        {"video/AV1", nil, false},
+		"Opus":            {"audio/opus", []byte{0xAA}, true},
+		"PCMU":            {"audio/PCMU", []byte{0xAA}, true},
+		"PCMA":            {"audio/PCMA", []byte{0xAA}, true},
+		"G722":            {"audio/G722", []byte{0xAA}, true},
+		"CaseInsensitive": {"VIDEO/vp8", []byte{0x10, 0xAA}, true},
+		"Unsupported":     {"video/unknown", []byte{0x10, 0xAA}, false},
+	} {
+		if got := IsPartitionHead(test.mimeType, test.payload); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, got)
+		}
+	}
+}
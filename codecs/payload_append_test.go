@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type appendPayloader interface {
+	Payload(mtu uint16, payload []byte) [][]byte
+	PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte
+}
+
+func appendPayloaders() map[string]func() appendPayloader {
+	return map[string]func() appendPayloader{
+		"G711": func() appendPayloader { return &G711Payloader{} },
+		"G722": func() appendPayloader { return &G722Payloader{} },
+		"Opus": func() appendPayloader { return &OpusPayloader{} },
+		"VP8":  func() appendPayloader { return &VP8Payloader{EnablePictureID: true} },
+		"VP9": func() appendPayloader {
+			return &VP9Payloader{InitialPictureIDFn: func() uint16 { return 42 }}
+		},
+		"H264": func() appendPayloader { return &H264Payloader{} },
+		"H265": func() appendPayloader { return &H265Payloader{} },
+		"AV1":  func() appendPayloader { return &AV1Payloader{} },
+	}
+}
+
+func appendTestFrame(name string) []byte {
+	switch name {
+	case "H264":
+		return append([]byte{0x00, 0x00, 0x00, 0x01, 0x65}, make([]byte, 300)...)
+	case "H265":
+		return append([]byte{0x00, 0x00, 0x00, 0x01, 0x26, 0x01}, make([]byte, 300)...)
+	case "VP9":
+		return append([]byte{0x82, 0x49, 0x83, 0x42, 0x00, 0x77, 0xf0, 0x32, 0x34}, make([]byte, 300)...)
+	default:
+		return make([]byte, 300)
+	}
+}
+
+func TestPayloadAppend(t *testing.T) {
+	for name, newPayloader := range appendPayloaders() {
+		name, newPayloader := name, newPayloader
+		t.Run(name, func(t *testing.T) {
+			frame := appendTestFrame(name)
+			reference, appender := newPayloader(), newPayloader()
+
+			var dst [][]byte
+			for i := 0; i < 3; i++ {
+				expected := reference.Payload(100, frame)
+				if len(expected) == 0 {
+					t.Fatal("Expected payloads")
+				}
+
+				prev := dst
+				dst = appender.PayloadAppend(dst[:0], 100, frame)
+				if !reflect.DeepEqual(dst, expected) {
+					t.Fatalf("Call %d: expected %v, got %v", i, expected, dst)
+				}
+				if i > 0 && &prev[0] != &dst[0] {
+					t.Fatalf("Call %d: expected the outer slice to be reused", i)
+				}
+			}
+
+			// Payloads are appended after the existing entries
+			existing := []byte{0xAA}
+			expected := append([][]byte{existing}, reference.Payload(100, frame)...)
+			if actual := appender.PayloadAppend([][]byte{existing}, 100, frame); !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("Expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func BenchmarkPayloadAppend(b *testing.B) {
+	for name, newPayloader := range appendPayloaders() {
+		name, newPayloader := name, newPayloader
+		frame := appendTestFrame(name)
+
+		b.Run(name+"/Payload", func(b *testing.B) {
+			payloader := newPayloader()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = payloader.Payload(100, frame)
+			}
+		})
+
+		b.Run(name+"/PayloadAppend", func(b *testing.B) {
+			payloader := newPayloader()
+			var dst [][]byte
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dst = payloader.PayloadAppend(dst[:0], 100, frame)
+			}
+		})
+	}
+}
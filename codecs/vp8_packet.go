@@ -6,15 +6,55 @@ package codecs
 // VP8Payloader payloads VP8 packets.
 type VP8Payloader struct {
 	EnablePictureID bool
-	pictureID       uint16
+
+	// PictureIDLength is the length of the picture ID in bits, either 7 or 15.
+	// When unset the length is picked from the current picture ID value.
+	PictureIDLength uint8
+
+	pictureID uint16
 }
 
 const (
 	vp8HeaderSize = 1
+
+	vp8PictureIDLengthShort = 7
+	vp8PictureIDLengthLong  = 15
 )
 
 // Payload fragments a VP8 packet across one or more byte arrays.
-func (p *VP8Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:cyclop
+func (p *VP8Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	return p.PayloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments a VP8 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls.
+func (p *VP8Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads := dst
+	if !p.packetize(mtu, payload, appendPackets(&payloads)) {
+		return dst
+	}
+
+	p.pictureID++
+	if p.PictureIDLength == vp8PictureIDLengthShort {
+		p.pictureID &= 0x7F
+	} else {
+		p.pictureID &= 0x7FFF
+	}
+
+	return payloads
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *VP8Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	p.packetize(mtu, payload, countPackets(&count))
+
+	return count
+}
+
+// packetize splits payload into packets written to next, and returns false if
+// it doesn't fit in packets of mtu bytes.
+func (p *VP8Payloader) packetize(mtu uint16, payload []byte, next packetSink) bool {
 	/*
 	 * https://tools.ietf.org/html/rfc7741#section-4.2
 	 *
@@ -36,64 +76,68 @@ func (p *VP8Payloader) Payload(mtu uint16, payload []byte) [][]byte { //nolint:c
 	 *     first packet of each encoded frame.
 	 */
 
-	usingHeaderSize := vp8HeaderSize
-	if p.EnablePictureID {
-		switch {
-		case p.pictureID == 0:
-		case p.pictureID < 128:
-			usingHeaderSize = vp8HeaderSize + 2
-		default:
-			usingHeaderSize = vp8HeaderSize + 3
-		}
-	}
-
+	usingHeaderSize := p.headerSize()
 	maxFragmentSize := int(mtu) - usingHeaderSize
 
 	payloadData := payload
 	payloadDataRemaining := len(payload)
 
 	payloadDataIndex := 0
-	var payloads [][]byte
 
 	// Make sure the fragment/payload size is correct
 	if minInt(maxFragmentSize, payloadDataRemaining) <= 0 {
-		return payloads
+		return false
 	}
 	first := true
 	for payloadDataRemaining > 0 {
 		currentFragmentSize := minInt(maxFragmentSize, payloadDataRemaining)
-		out := make([]byte, usingHeaderSize+currentFragmentSize)
-
-		if first {
-			out[0] = 0x10
-			first = false
-		}
-		if p.EnablePictureID {
-			switch usingHeaderSize {
-			case vp8HeaderSize:
-			case vp8HeaderSize + 2:
-				out[0] |= 0x80
-				out[1] |= 0x80
-				out[2] |= uint8(p.pictureID & 0x7F) // nolint: gosec // G115 false positive
-			case vp8HeaderSize + 3:
-				out[0] |= 0x80
-				out[1] |= 0x80
-				out[2] |= 0x80 | uint8((p.pictureID>>8)&0x7F) // nolint: gosec // G115 false positive
-				out[3] |= uint8(p.pictureID & 0xFF)           // nolint: gosec // G115 false positive
+		if out := next(usingHeaderSize + currentFragmentSize); out != nil {
+			if first {
+				out[0] = 0x10
+			}
+			if p.EnablePictureID {
+				switch usingHeaderSize {
+				case vp8HeaderSize:
+				case vp8HeaderSize + 2:
+					out[0] |= 0x80
+					out[1] |= 0x80
+					out[2] |= uint8(p.pictureID & 0x7F) // nolint: gosec // G115 false positive
+				case vp8HeaderSize + 3:
+					out[0] |= 0x80
+					out[1] |= 0x80
+					out[2] |= 0x80 | uint8((p.pictureID>>8)&0x7F) // nolint: gosec // G115 false positive
+					out[3] |= uint8(p.pictureID & 0xFF)           // nolint: gosec // G115 false positive
+				}
 			}
-		}
 
-		copy(out[usingHeaderSize:], payloadData[payloadDataIndex:payloadDataIndex+currentFragmentSize])
-		payloads = append(payloads, out)
+			copy(out[usingHeaderSize:], payloadData[payloadDataIndex:payloadDataIndex+currentFragmentSize])
+		}
+		first = false
 
 		payloadDataRemaining -= currentFragmentSize
 		payloadDataIndex += currentFragmentSize
 	}
 
-	p.pictureID++
-	p.pictureID &= 0x7FFF
+	return true
+}
 
-	return payloads
+func (p *VP8Payloader) headerSize() int {
+	if !p.EnablePictureID {
+		return vp8HeaderSize
+	}
+
+	switch {
+	case p.PictureIDLength == vp8PictureIDLengthShort:
+		return vp8HeaderSize + 2
+	case p.PictureIDLength == vp8PictureIDLengthLong:
+		return vp8HeaderSize + 3
+	case p.pictureID == 0:
+		return vp8HeaderSize
+	case p.pictureID < 128:
+		return vp8HeaderSize + 2
+	default:
+		return vp8HeaderSize + 3
+	}
 }
 
 // VP8Packet represents the VP8 header that is stored in the payload of an RTP Packet.
@@ -228,10 +272,11 @@ func (*VP8PartitionHeadChecker) IsPartitionHead(packet []byte) bool {
 }
 
 // IsPartitionHead checks whether if this is a head of the VP8 partition.
+// Only the start of the first partition (S=1, PID=0) begins a VP8 frame.
 func (*VP8Packet) IsPartitionHead(payload []byte) bool {
 	if len(payload) < 1 {
 		return false
 	}
 
-	return (payload[0] & 0x10) != 0
+	return (payload[0]&0x10) != 0 && (payload[0]&0x07) == 0
 }
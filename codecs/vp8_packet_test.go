@@ -134,6 +134,112 @@ func TestVP8Packet_Unmarshal(t *testing.T) { //nolint:cyclop
 	}
 }
 
+func TestVP8Packet_Unmarshal_Descriptor(t *testing.T) {
+	cases := map[string]struct {
+		b   []byte
+		pkt VP8Packet
+		err error
+	}{
+		"Minimal": {
+			b: []byte{0x10, 0xAA},
+			pkt: VP8Packet{
+				S:       1,
+				Payload: []byte{0xAA},
+			},
+		},
+		"NonReferencePartition": {
+			b: []byte{0x23, 0xAA},
+			pkt: VP8Packet{
+				N:       1,
+				PID:     3,
+				Payload: []byte{0xAA},
+			},
+		},
+		"PictureID7Bit": {
+			b: []byte{0x90, 0x80, 0x12, 0xAA},
+			pkt: VP8Packet{
+				X:         1,
+				S:         1,
+				I:         1,
+				PictureID: 0x12,
+				Payload:   []byte{0xAA},
+			},
+		},
+		"PictureID15Bit": {
+			b: []byte{0x90, 0x80, 0x81, 0x23, 0xAA},
+			pkt: VP8Packet{
+				X:         1,
+				S:         1,
+				I:         1,
+				PictureID: 0x0123,
+				Payload:   []byte{0xAA},
+			},
+		},
+		"AllExtensions": {
+			b: []byte{0x90, 0xF0, 0xFF, 0xFF, 0x05, 0xA9, 0xAA},
+			pkt: VP8Packet{
+				X:         1,
+				S:         1,
+				I:         1,
+				L:         1,
+				T:         1,
+				K:         1,
+				PictureID: 0x7FFF,
+				TL0PICIDX: 0x05,
+				TID:       2,
+				Y:         1,
+				KEYIDX:    0x09,
+				Payload:   []byte{0xAA},
+			},
+		},
+		"KeyIndexOnly": {
+			b: []byte{0x80, 0x10, 0x1F, 0xAA},
+			pkt: VP8Packet{
+				X:       1,
+				K:       1,
+				KEYIDX:  0x1F,
+				Payload: []byte{0xAA},
+			},
+		},
+		"MissingTL0PICIDX": {
+			b:   []byte{0x80, 0x40},
+			err: errShortPacket,
+		},
+		"MissingPictureIDExtension": {
+			b:   []byte{0x80, 0x80, 0x81},
+			err: errShortPacket,
+		},
+		"MissingTIDKEYIDX": {
+			b:   []byte{0x80, 0x20},
+			err: errShortPacket,
+		},
+	}
+	for name, testCase := range cases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			p := VP8Packet{}
+			raw, err := p.Unmarshal(testCase.b)
+			if testCase.err != nil {
+				if raw != nil {
+					t.Error("Result should be nil in case of error")
+				}
+				if !errors.Is(err, testCase.err) {
+					t.Errorf("Error should be '%v', got '%v'", testCase.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.pkt, p) {
+				t.Errorf("Unmarshalled packet expected to be:\n %v\ngot:\n %v", testCase.pkt, p)
+			}
+		})
+	}
+}
+
 func TestVP8Payloader_Payload(t *testing.T) {
 	testCases := map[string]struct {
 		payloader VP8Payloader
@@ -193,6 +299,46 @@ func TestVP8Payloader_Payload(t *testing.T) {
 				},
 			},
 		},
+		"WithPictureID_Short_Wrap": {
+			payloader: VP8Payloader{
+				EnablePictureID: true,
+				PictureIDLength: 7,
+				pictureID:       0x7F,
+			},
+			mtu: 5,
+			payload: [][]byte{
+				{0x90, 0x90},
+				{0x91, 0x91},
+			},
+			expected: [][][]byte{
+				{
+					{0x90, 0x80, 0x7F, 0x90, 0x90},
+				},
+				{
+					{0x90, 0x80, 0x00, 0x91, 0x91},
+				},
+			},
+		},
+		"WithPictureID_Long_Wrap": {
+			payloader: VP8Payloader{
+				EnablePictureID: true,
+				PictureIDLength: 15,
+				pictureID:       0x7FFF,
+			},
+			mtu: 6,
+			payload: [][]byte{
+				{0x90, 0x90},
+				{0x91, 0x91},
+			},
+			expected: [][][]byte{
+				{
+					{0x90, 0x80, 0xFF, 0xFF, 0x90, 0x90},
+				},
+				{
+					{0x90, 0x80, 0x80, 0x00, 0x91, 0x91},
+				},
+			},
+		},
 	}
 	for name, testCase := range testCases {
 		testCase := testCase
@@ -239,6 +385,11 @@ func TestVP8IsPartitionHead(t *testing.T) {
 			t.Fatal("Packet with S flag should be the head of a new partition")
 		}
 	})
+	t.Run("SFlagONWithPID", func(t *testing.T) {
+		if vp8.IsPartitionHead([]byte{0x11, 0x00, 0x00, 0x00}) {
+			t.Fatal("Packet starting a partition other than the first should not be the head of a new frame")
+		}
+	})
 	t.Run("SFlagOFF", func(t *testing.T) {
 		if vp8.IsPartitionHead([]byte{0x00, 0x00, 0x00, 0x00}) {
 			t.Fatal("Packet without S flag should not be the head of a new partition")
@@ -19,43 +19,218 @@ type VP9Payloader struct {
 	// InitialPictureIDFn is a function that returns random initial picture ID.
 	InitialPictureIDFn func() uint16
 
+	// PictureIDLength is the length of the picture ID in bits, either 7 or 15.
+	// The 15-bit extended form (M=1) is used when unset.
+	PictureIDLength uint8
+
 	pictureID   uint16
 	initialized bool
+
+	layerIndices         bool
+	spatialID            uint8
+	interLayerDependency bool
+	pictureStarted       bool
+	temporalID           uint8
+
+	tl0PicIdxEnabled bool
+	tl0PicIdx        uint8
+	tl0Started       bool
 }
 
+// VP9MaxPDiff is the maximum number of reference indices (P_DIFF) of a
+// flexible mode picture, as defined by the VP9 RTP payload format.
+const VP9MaxPDiff = 3
+
 const (
 	maxSpatialLayers = 5
-	maxVP9RefPics    = 3
+
+	vp9PictureIDLengthShort = 7
+
+	vp9SpatialIDMask  = 0x07
+	vp9TemporalIDMask = 0x07
 )
 
+// SetSpatialID makes the flexible mode payloader emit layer indices (L=1)
+// with the given spatial layer ID for the following frames. Frames of the
+// spatial layers of a picture share its picture ID, which advances when a
+// frame with spatial ID 0 is payloaded.
+func (p *VP9Payloader) SetSpatialID(sid uint8) {
+	p.layerIndices = true
+	p.spatialID = sid & vp9SpatialIDMask
+}
+
+// SetInterLayerDependency sets the D bit of the layer indices, signaling that
+// the following frames depend on the spatial layer immediately below them.
+// It must only be set for spatial IDs greater than 0.
+func (p *VP9Payloader) SetInterLayerDependency(dependent bool) {
+	p.interLayerDependency = dependent
+}
+
+// SetTemporalID sets the temporal layer ID written in the layer indices of the
+// following frames.
+func (p *VP9Payloader) SetTemporalID(tid uint8) {
+	p.temporalID = tid & vp9TemporalIDMask
+}
+
+// SetTL0PICIDX makes the non-flexible mode payloader emit layer indices (L=1)
+// with a TL0PICIDX, which is set to tl0PicIdx for the next picture. It is then
+// incremented for every picture with temporal ID 0, so that pictures of the
+// upper temporal layers carry the index of the preceding base layer picture.
+func (p *VP9Payloader) SetTL0PICIDX(tl0PicIdx uint8) {
+	p.tl0PicIdxEnabled = true
+	p.tl0PicIdx = tl0PicIdx
+	p.tl0Started = false
+}
+
 // Payload fragments an VP9 packet across one or more byte arrays.
 func (p *VP9Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	return p.PayloadAppend(nil, mtu, payload)
+}
+
+// PayloadAppend fragments an VP9 packet like Payload and appends the payloads to dst.
+// Passing dst[:0] reuses the outer slice across calls.
+func (p *VP9Payloader) PayloadAppend(dst [][]byte, mtu uint16, payload []byte) [][]byte {
 	if !p.initialized {
 		if p.InitialPictureIDFn == nil {
 			p.InitialPictureIDFn = func() uint16 {
 				return uint16(globalMathRandomGenerator.Intn(0x7FFF)) // nolint: gosec
 			}
 		}
-		p.pictureID = p.InitialPictureIDFn() & 0x7FFF
+		p.pictureID = p.InitialPictureIDFn() & p.maxPictureID()
 		p.initialized = true
 	}
 
-	var payloads [][]byte
+	if !p.FlexibleMode {
+		p.nextTL0PicIdx()
+		payloads := p.appendPayloads(dst, mtu, payload)
+		p.nextPictureID()
+
+		return payloads
+	}
+
+	if !p.layerIndices {
+		payloads := p.appendPayloads(dst, mtu, payload)
+		p.nextPictureID()
+
+		return payloads
+	}
+
+	if p.spatialID == 0 && p.pictureStarted {
+		p.nextPictureID()
+	}
+	p.pictureStarted = true
+
+	return p.appendPayloads(dst, mtu, payload)
+}
+
+// appendPayloads appends the payloads of payload to dst, or returns dst
+// unchanged if it can't be payloaded.
+func (p *VP9Payloader) appendPayloads(dst [][]byte, mtu uint16, payload []byte) [][]byte {
+	payloads := dst
+	if !p.packetize(mtu, payload, appendPackets(&payloads)) {
+		return dst
+	}
+
+	return payloads
+}
+
+// packetize splits payload into packets written to next, and returns false if
+// it can't be payloaded.
+func (p *VP9Payloader) packetize(mtu uint16, payload []byte, next packetSink) bool {
 	if p.FlexibleMode {
-		payloads = p.payloadFlexible(mtu, payload)
-	} else {
-		payloads = p.payloadNonFlexible(mtu, payload)
+		return p.payloadFlexible(mtu, payload, next)
 	}
 
+	return p.payloadNonFlexible(mtu, payload, next)
+}
+
+func (p *VP9Payloader) nextPictureID() {
 	p.pictureID++
-	if p.pictureID >= 0x8000 {
+	if p.pictureID > p.maxPictureID() {
 		p.pictureID = 0
 	}
+}
 
-	return payloads
+func (p *VP9Payloader) nextTL0PicIdx() {
+	if !p.tl0PicIdxEnabled || p.temporalID != 0 {
+		return
+	}
+
+	if p.tl0Started {
+		p.tl0PicIdx++
+	}
+	p.tl0Started = true
+}
+
+// PayloadCount returns the number of payloads Payload would produce, without allocating them.
+func (p *VP9Payloader) PayloadCount(mtu uint16, payload []byte) int {
+	count := 0
+	if !p.packetize(mtu, payload, countPackets(&count)) {
+		return 0
+	}
+
+	return count
+}
+
+func (p *VP9Payloader) maxPictureID() uint16 {
+	if p.PictureIDLength == vp9PictureIDLengthShort {
+		return 0x7F
+	}
+
+	return 0x7FFF
+}
+
+func (p *VP9Payloader) pictureIDSize() int {
+	if p.PictureIDLength == vp9PictureIDLengthShort {
+		return 1
+	}
+
+	return 2
 }
 
-func (p *VP9Payloader) payloadFlexible(mtu uint16, payload []byte) [][]byte {
+func (p *VP9Payloader) flexibleHeaderSize() int {
+	headerSize := 1 + p.pictureIDSize()
+	if p.layerIndices {
+		headerSize++
+	}
+
+	return headerSize
+}
+
+func (p *VP9Payloader) nonFlexibleHeaderSize() int {
+	headerSize := 1 + p.pictureIDSize()
+	if p.tl0PicIdxEnabled {
+		headerSize += 2
+	}
+
+	return headerSize
+}
+
+// layerIndicesByte returns the TID, U, SID and D fields of the layer indices.
+func (p *VP9Payloader) layerIndicesByte() byte {
+	b := p.temporalID<<5 | p.spatialID<<1
+	if p.interLayerDependency {
+		b |= 0x01 // D=1
+	}
+
+	return b
+}
+
+// writePictureID writes the picture ID and returns the number of bytes written.
+func (p *VP9Payloader) writePictureID(out []byte) int {
+	if p.PictureIDLength == vp9PictureIDLengthShort {
+		out[0] = byte(p.pictureID) & 0x7F // M=0
+
+		return 1
+	}
+
+	out[0] = byte(p.pictureID>>8) | 0x80 // M=1
+	out[1] = byte(p.pictureID)
+
+	return 2
+}
+
+func (p *VP9Payloader) payloadFlexible(mtu uint16, payload []byte, next packetSink) bool {
 	/*
 	 * Flexible mode (F=1)
 	 *        0 1 2 3 4 5 6 7
@@ -75,42 +250,44 @@ func (p *VP9Payloader) payloadFlexible(mtu uint16, payload []byte) [][]byte {
 	 *       +-+-+-+-+-+-+-+-+
 	 */
 
-	headerSize := 3
+	headerSize := p.flexibleHeaderSize()
 	maxFragmentSize := int(mtu) - headerSize
 	payloadDataRemaining := len(payload)
 	payloadDataIndex := 0
-	var payloads [][]byte
 
 	if minInt(maxFragmentSize, payloadDataRemaining) <= 0 {
-		return [][]byte{}
+		return false
 	}
 
 	for payloadDataRemaining > 0 {
 		currentFragmentSize := minInt(maxFragmentSize, payloadDataRemaining)
-		out := make([]byte, headerSize+currentFragmentSize)
+		if out := next(headerSize + currentFragmentSize); out != nil {
+			out[0] = 0x90 // F=1, I=1
+			if payloadDataIndex == 0 {
+				out[0] |= 0x08 // B=1
+			}
+			if payloadDataRemaining == currentFragmentSize {
+				out[0] |= 0x04 // E=1
+			}
 
-		out[0] = 0x90 // F=1, I=1
-		if payloadDataIndex == 0 {
-			out[0] |= 0x08 // B=1
-		}
-		if payloadDataRemaining == currentFragmentSize {
-			out[0] |= 0x04 // E=1
-		}
+			off := 1 + p.writePictureID(out[1:])
 
-		out[1] = byte(p.pictureID>>8) | 0x80
-		out[2] = byte(p.pictureID)
+			if p.layerIndices {
+				out[0] |= 0x20 // L=1
+				out[off] = p.layerIndicesByte()
+			}
 
-		copy(out[headerSize:], payload[payloadDataIndex:payloadDataIndex+currentFragmentSize])
-		payloads = append(payloads, out)
+			copy(out[headerSize:], payload[payloadDataIndex:payloadDataIndex+currentFragmentSize])
+		}
 
 		payloadDataRemaining -= currentFragmentSize
 		payloadDataIndex += currentFragmentSize
 	}
 
-	return payloads
+	return true
 }
 
-func (p *VP9Payloader) payloadNonFlexible(mtu uint16, payload []byte) [][]byte { //nolint:cyclop
+func (p *VP9Payloader) payloadNonFlexible(mtu uint16, payload []byte, next packetSink) bool { //nolint:cyclop
 	/*
 	 * Non-flexible mode (F=0)
 	 *        0 1 2 3 4 5 6 7
@@ -133,79 +310,80 @@ func (p *VP9Payloader) payloadNonFlexible(mtu uint16, payload []byte) [][]byte {
 	var header vp9.Header
 	err := header.Unmarshal(payload)
 	if err != nil {
-		return [][]byte{}
+		return false
 	}
 
 	payloadDataRemaining := len(payload)
 	payloadDataIndex := 0
-	var payloads [][]byte
 
 	for payloadDataRemaining > 0 {
-		var headerSize int
+		headerSize := p.nonFlexibleHeaderSize()
 		if !header.NonKeyFrame && payloadDataIndex == 0 {
-			headerSize = 3 + 8
-		} else {
-			headerSize = 3
+			headerSize += 8
 		}
 
 		maxFragmentSize := int(mtu) - headerSize
 		currentFragmentSize := minInt(maxFragmentSize, payloadDataRemaining)
 		if currentFragmentSize <= 0 {
-			return [][]byte{}
+			return false
 		}
 
-		out := make([]byte, headerSize+currentFragmentSize)
+		if out := next(headerSize + currentFragmentSize); out != nil {
+			out[0] = 0x80 | 0x01 // I=1, Z=1
 
-		out[0] = 0x80 | 0x01 // I=1, Z=1
+			if header.NonKeyFrame {
+				out[0] |= 0x40 // P=1
+			}
+			if payloadDataIndex == 0 {
+				out[0] |= 0x08 // B=1
+			}
+			if payloadDataRemaining == currentFragmentSize {
+				out[0] |= 0x04 // E=1
+			}
 
-		if header.NonKeyFrame {
-			out[0] |= 0x40 // P=1
-		}
-		if payloadDataIndex == 0 {
-			out[0] |= 0x08 // B=1
-		}
-		if payloadDataRemaining == currentFragmentSize {
-			out[0] |= 0x04 // E=1
-		}
+			off := 1 + p.writePictureID(out[1:])
 
-		out[1] = byte(p.pictureID>>8) | 0x80
-		out[2] = byte(p.pictureID)
-		off := 3
+			if p.tl0PicIdxEnabled {
+				out[0] |= 0x20 // L=1
+				out[off] = p.layerIndicesByte()
+				out[off+1] = p.tl0PicIdx
+				off += 2
+			}
 
-		if !header.NonKeyFrame && payloadDataIndex == 0 {
-			out[0] |= 0x02         // V=1
-			out[off] = 0x10 | 0x08 // N_S=0, Y=1, G=1
-			off++
+			if !header.NonKeyFrame && payloadDataIndex == 0 {
+				out[0] |= 0x02         // V=1
+				out[off] = 0x10 | 0x08 // N_S=0, Y=1, G=1
+				off++
 
-			width := header.Width()
-			out[off] = byte(width >> 8)
-			off++
-			out[off] = byte(width & 0xFF)
-			off++
+				width := header.Width()
+				out[off] = byte(width >> 8)
+				off++
+				out[off] = byte(width & 0xFF)
+				off++
 
-			height := header.Height()
-			out[off] = byte(height >> 8)
-			off++
-			out[off] = byte(height & 0xFF)
-			off++
+				height := header.Height()
+				out[off] = byte(height >> 8)
+				off++
+				out[off] = byte(height & 0xFF)
+				off++
 
-			out[off] = 0x01 // N_G=1
-			off++
+				out[off] = 0x01 // N_G=1
+				off++
 
-			out[off] = 1<<4 | 1<<2 // TID=0, U=1, R=1
-			off++
+				out[off] = 1<<4 | 1<<2 // TID=0, U=1, R=1
+				off++
 
-			out[off] = 0x01 // P_DIFF=1
-		}
+				out[off] = 0x01 // P_DIFF=1
+			}
 
-		copy(out[headerSize:], payload[payloadDataIndex:payloadDataIndex+currentFragmentSize])
-		payloads = append(payloads, out)
+			copy(out[headerSize:], payload[payloadDataIndex:payloadDataIndex+currentFragmentSize])
+		}
 
 		payloadDataRemaining -= currentFragmentSize
 		payloadDataIndex += currentFragmentSize
 	}
 
-	return payloads
+	return true
 }
 
 // VP9Packet represents the VP9 header that is stored in the payload of an RTP Packet.
@@ -246,6 +424,10 @@ type VP9Packet struct {
 
 	Payload []byte
 
+	// MaxPDiff overrides the maximum number of reference indices accepted by
+	// Unmarshal, for non-standard streams. Zero means VP9MaxPDiff.
+	MaxPDiff int
+
 	videoDepacketizer
 }
 
@@ -267,6 +449,10 @@ func (p *VP9Packet) Unmarshal(packet []byte) ([]byte, error) { // nolint:cyclop
 	p.V = packet[0]&0x02 != 0
 	p.Z = packet[0]&0x01 != 0
 
+	if p.PDiff != nil {
+		p.PDiff = p.PDiff[:0]
+	}
+
 	pos := 1
 	var err error
 
@@ -389,6 +575,19 @@ func (p *VP9Packet) parseLayerInfoNonFlexibleMode(packet []byte, pos int) (int,
 	return pos, nil
 }
 
+// ReferenceCount returns the number of reference indices (P_DIFF) of the packet.
+func (p *VP9Packet) ReferenceCount() int {
+	return len(p.PDiff)
+}
+
+func (p *VP9Packet) maxPDiff() int {
+	if p.MaxPDiff > 0 {
+		return p.MaxPDiff
+	}
+
+	return VP9MaxPDiff
+}
+
 // Reference indices: .
 /*
 *      +-+-+-+-+-+-+-+-+                P=1,F=1: At least one reference index
@@ -407,7 +606,7 @@ func (p *VP9Packet) parseRefIndices(packet []byte, pos int) (int, error) {
 		if packet[pos]&0x01 == 0 {
 			break
 		}
-		if len(p.PDiff) >= maxVP9RefPics {
+		if len(p.PDiff) >= p.maxPDiff() {
 			return pos, errTooManyPDiff
 		}
 		pos++
@@ -520,3 +719,11 @@ func (*VP9Packet) IsPartitionHead(payload []byte) bool {
 
 	return (payload[0] & 0x08) != 0
 }
+
+// IsPartitionTail checks if this is the last packet of a frame: the marker bit
+// is set, or the E bit of the payload descriptor ends the frame. With spatial
+// scalability, E ends each layer frame while the marker bit only ends the
+// picture, so every spatial layer of a picture is reported as a partition.
+func (*VP9Packet) IsPartitionTail(marker bool, payload []byte) bool {
+	return marker || (len(payload) > 0 && (payload[0]&0x04) != 0)
+}
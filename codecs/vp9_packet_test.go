@@ -4,6 +4,7 @@
 package codecs
 
 import (
+	"bytes"
 	"errors"
 	"math/rand"
 	"reflect"
@@ -214,19 +215,59 @@ func TestVP9Packet_Unmarshal(t *testing.T) {
 	}
 }
 
+func TestVP9Packet_ReferenceCount(t *testing.T) {
+	// Picture ID followed by VP9MaxPDiff reference indices, the last one with N=0
+	maxRefs := []byte{0xD0, 0x02, 0x03, 0x05, 0x06, 0xAA}
+	tooManyRefs := []byte{0xD0, 0x02, 0x03, 0x05, 0x07, 0x08, 0xAA}
+
+	pkt := VP9Packet{}
+	if _, err := pkt.Unmarshal(maxRefs); err != nil {
+		t.Fatal(err)
+	}
+	if pkt.ReferenceCount() != VP9MaxPDiff {
+		t.Fatalf("Expected %d references, got %d", VP9MaxPDiff, pkt.ReferenceCount())
+	}
+
+	// The references of the previous packet are not kept
+	if _, err := pkt.Unmarshal([]byte{0xD0, 0x02, 0x04, 0xAA}); err != nil {
+		t.Fatal(err)
+	}
+	if pkt.ReferenceCount() != 1 {
+		t.Fatalf("Expected 1 reference, got %d", pkt.ReferenceCount())
+	}
+
+	if _, err := (&VP9Packet{}).Unmarshal(tooManyRefs); !errors.Is(err, errTooManyPDiff) {
+		t.Fatalf("Expected %v, got %v", errTooManyPDiff, err)
+	}
+
+	pkt = VP9Packet{MaxPDiff: VP9MaxPDiff + 1}
+	if _, err := pkt.Unmarshal(tooManyRefs); err != nil {
+		t.Fatal(err)
+	}
+	if pkt.ReferenceCount() != VP9MaxPDiff+1 {
+		t.Fatalf("Expected %d references, got %d", VP9MaxPDiff+1, pkt.ReferenceCount())
+	}
+	if !reflect.DeepEqual(pkt.PDiff, []uint8{0x01, 0x02, 0x03, 0x04}) {
+		t.Fatalf("Unexpected reference indices %v", pkt.PDiff)
+	}
+}
+
 func TestVP9Payloader_Payload(t *testing.T) { //nolint:cyclop
 	r0 := int(rand.New(rand.NewSource(0)).Int31n(0x7FFF)) //nolint:gosec
 	var rands [][2]byte
+	var shortRands []byte
 	for i := 0; i < 10; i++ {
 		rands = append(rands, [2]byte{byte(r0>>8) | 0x80, byte(r0 & 0xFF)})
+		shortRands = append(shortRands, byte(r0&0x7F))
 		r0++
 	}
 
 	cases := map[string]struct {
-		b        [][]byte
-		flexible bool
-		mtu      uint16
-		res      [][]byte
+		b               [][]byte
+		flexible        bool
+		pictureIDLength uint8
+		mtu             uint16
+		res             [][]byte
 	}{
 		"flexible NilPayload": {
 			b:        [][]byte{nil},
@@ -277,6 +318,26 @@ func TestVP9Payloader_Payload(t *testing.T) { //nolint:cyclop
 				{0x9C, rands[1][0], rands[1][1], 0x04},
 			},
 		},
+		"flexible TwoFramesShortPictureID": {
+			b:               [][]byte{{0x01, 0x02, 0x03}, {0x04}},
+			flexible:        true,
+			pictureIDLength: 7,
+			mtu:             4,
+			res: [][]byte{
+				{0x98, shortRands[0], 0x01, 0x02},
+				{0x94, shortRands[0], 0x03},
+				{0x9C, shortRands[1], 0x04},
+			},
+		},
+		"flexible LongPictureID": {
+			b:               [][]byte{{0x01, 0x02}},
+			flexible:        true,
+			pictureIDLength: 15,
+			mtu:             10,
+			res: [][]byte{
+				{0x9C, rands[0][0], rands[0][1], 0x01, 0x02},
+			},
+		},
 		"non-flexible NilPayload": {
 			b:   [][]byte{nil},
 			mtu: 100,
@@ -332,6 +393,16 @@ func TestVP9Payloader_Payload(t *testing.T) { //nolint:cyclop
 				},
 			},
 		},
+		"non-flexible OnePacket key frame short picture ID": {
+			b:               [][]byte{{0x82, 0x49, 0x83, 0x42, 0x0, 0x77, 0xf0, 0x32, 0x34}},
+			pictureIDLength: 7,
+			mtu:             20,
+			res: [][]byte{{
+				0x8f, 0x74, 0x18, 0x07, 0x80, 0x03, 0x24, 0x01,
+				0x14, 0x01, 0x82, 0x49, 0x83, 0x42, 0x00, 0x77,
+				0xf0, 0x32, 0x34,
+			}},
+		},
 		"non-flexible OnePacket non key frame": {
 			b:   [][]byte{{0x86, 0x0, 0x40, 0x92, 0xe1, 0x31, 0x42, 0x8c, 0xc0, 0x40}},
 			mtu: 20,
@@ -345,7 +416,8 @@ func TestVP9Payloader_Payload(t *testing.T) { //nolint:cyclop
 	for name, testCase := range cases {
 		t.Run(name, func(t *testing.T) {
 			pck := VP9Payloader{
-				FlexibleMode: testCase.flexible,
+				FlexibleMode:    testCase.flexible,
+				PictureIDLength: testCase.pictureIDLength,
 				InitialPictureIDFn: func() uint16 {
 					return uint16(rand.New(rand.NewSource(0)).Int31n(0x7FFF)) //nolint:gosec
 				},
@@ -390,6 +462,136 @@ func TestVP9Payloader_Payload(t *testing.T) { //nolint:cyclop
 			pPrev = packet
 		}
 	})
+
+	t.Run("ShortPictureIDOverflow", func(t *testing.T) {
+		pck := VP9Payloader{
+			FlexibleMode:    true,
+			PictureIDLength: 7,
+			InitialPictureIDFn: func() uint16 {
+				return 0x7E
+			},
+		}
+		expected := []uint16{0x7E, 0x7F, 0x00, 0x01}
+		for _, pictureID := range expected {
+			res := pck.Payload(4, []byte{0x01})
+			if len(res[0]) != 3 {
+				t.Fatalf("Payload with short picture ID must be 3 bytes, got %d", len(res[0]))
+			}
+			packet := VP9Packet{}
+			if _, err := packet.Unmarshal(res[0]); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res[0][1]&0x80 != 0 {
+				t.Error("M bit must not be set for short picture ID")
+			}
+			if packet.PictureID != pictureID {
+				t.Errorf("Picture ID expected to be %d, got %d", pictureID, packet.PictureID)
+			}
+		}
+	})
+}
+
+func TestVP9Payloader_SpatialLayers(t *testing.T) {
+	pck := VP9Payloader{
+		FlexibleMode: true,
+		InitialPictureIDFn: func() uint16 {
+			return 8692
+		},
+	}
+
+	payloadLayer := func(sid uint8, dependent bool, payload []byte) [][]byte {
+		pck.SetSpatialID(sid)
+		pck.SetInterLayerDependency(dependent)
+
+		return pck.Payload(6, payload)
+	}
+
+	expected := [][][]byte{
+		{{0xBC, 0xA1, 0xF4, 0x00, 0x01, 0x02}},
+		{{0xBC, 0xA1, 0xF4, 0x03, 0x03, 0x04}},
+		{{0xB8, 0xA1, 0xF5, 0x00, 0x05, 0x06}, {0xB4, 0xA1, 0xF5, 0x00, 0x07}},
+		{{0xBC, 0xA1, 0xF5, 0x03, 0x08}},
+	}
+	res := [][][]byte{
+		payloadLayer(0, false, []byte{0x01, 0x02}),
+		payloadLayer(1, true, []byte{0x03, 0x04}),
+		payloadLayer(0, false, []byte{0x05, 0x06, 0x07}),
+		payloadLayer(1, true, []byte{0x08}),
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Fatalf("Expected %v, got %v", expected, res)
+	}
+
+	for i, payloads := range res {
+		for _, payload := range payloads {
+			pkt := VP9Packet{}
+			if _, err := pkt.Unmarshal(payload); err != nil {
+				t.Fatal(err)
+			}
+			if !pkt.L || pkt.SID != uint8(i%2) || pkt.D != (i%2 == 1) {
+				t.Errorf("Frame %d: unexpected layer indices L=%v SID=%d D=%v", i, pkt.L, pkt.SID, pkt.D)
+			}
+		}
+	}
+
+	if count := pck.PayloadCount(6, []byte{0x05, 0x06, 0x07}); count != 2 {
+		t.Errorf("Expected PayloadCount 2, got %d", count)
+	}
+}
+
+func TestVP9Payloader_TL0PICIDX(t *testing.T) {
+	pck := VP9Payloader{
+		PictureIDLength: 7,
+		InitialPictureIDFn: func() uint16 {
+			return 0
+		},
+	}
+	pck.SetTL0PICIDX(254)
+
+	keyFrame := []byte{0x82, 0x49, 0x83, 0x42, 0x0, 0x77, 0xf0, 0x32, 0x34}
+	interFrame := []byte{0x86, 0x0, 0x40, 0x92, 0xe1, 0x31, 0x42, 0x8c, 0xc0, 0x40}
+
+	for i, test := range []struct {
+		frame     []byte
+		tid       uint8
+		tl0PicIdx uint8
+	}{
+		{keyFrame, 0, 254},
+		{interFrame, 1, 254},
+		{interFrame, 0, 255},
+		{interFrame, 1, 255},
+		{interFrame, 0, 0},
+	} {
+		pck.SetTemporalID(test.tid)
+		payloads := pck.Payload(1200, test.frame)
+		if len(payloads) != 1 {
+			t.Fatalf("Frame %d: expected 1 payload, got %d", i, len(payloads))
+		}
+
+		pkt := VP9Packet{}
+		if _, err := pkt.Unmarshal(payloads[0]); err != nil {
+			t.Fatal(err)
+		}
+		if !pkt.L || pkt.TID != test.tid || pkt.TL0PICIDX != test.tl0PicIdx {
+			t.Errorf("Frame %d: expected L=true TID=%d TL0PICIDX=%d, got L=%v TID=%d TL0PICIDX=%d",
+				i, test.tid, test.tl0PicIdx, pkt.L, pkt.TID, pkt.TL0PICIDX)
+		}
+		if !bytes.Equal(pkt.Payload, test.frame) {
+			t.Errorf("Frame %d: expected payload %v, got %v", i, test.frame, pkt.Payload)
+		}
+	}
+
+	// I, P, L, B, E and Z bits, picture ID, TID=1 and TL0PICIDX
+	pck.SetTemporalID(1)
+	expected := []byte{0xED, 0x05, 0x20, 0x00}
+	if res := pck.Payload(1200, interFrame); !bytes.Equal(res[0][:len(expected)], expected) {
+		t.Fatalf("Expected header %v, got %v", expected, res[0][:len(expected)])
+	}
+
+	// Four header bytes leave room for two bytes of the frame
+	if count := pck.PayloadCount(6, interFrame); count != 5 || len(pck.Payload(6, interFrame)) != 5 {
+		t.Errorf("Expected PayloadCount 5, got %d", count)
+	}
 }
 
 func TestVP9IsPartitionHead(t *testing.T) {
@@ -408,3 +610,23 @@ func TestVP9IsPartitionHead(t *testing.T) {
 		}
 	})
 }
+
+func TestVP9IsPartitionTail_SpatialLayers(t *testing.T) {
+	vp9 := &VP9Packet{}
+
+	// A picture with two spatial layers, the first one split in two packets.
+	// Flexible mode with layer indices: I=1, L=1, F=1, then the picture ID and SID.
+	for i, test := range []struct {
+		payload []byte
+		marker  bool
+		tail    bool
+	}{
+		{[]byte{0xB8, 0x01, 0x00, 0xAA}, false, false}, // SID 0, B=1
+		{[]byte{0xB4, 0x01, 0x00, 0xBB}, false, true},  // SID 0, E=1 ends the layer frame
+		{[]byte{0xBC, 0x01, 0x02, 0xCC}, true, true},   // SID 1, B=1 E=1 and the marker end the picture
+	} {
+		if got := vp9.IsPartitionTail(test.marker, test.payload); got != test.tail {
+			t.Errorf("packet %d: expected IsPartitionTail %v, got %v", i, test.tail, got)
+		}
+	}
+}
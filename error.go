@@ -13,12 +13,15 @@ var (
 	errTooSmall                           = errors.New("buffer too small")
 	errHeaderExtensionsNotEnabled         = errors.New("h.Extension not enabled")
 	errHeaderExtensionNotFound            = errors.New("extension not found")
+	errExtensionTooLarge                  = errors.New("header extension exceeds the maximum size")
+	errTooManyExtensions                  = errors.New("header extension elements exceed the maximum count")
+	errDuplicateExtensionID               = errors.New("header extension id appears more than once")
 
 	errRFC8285OneByteHeaderIDRange = errors.New(
 		"header extension id must be between 1 and 14 for RFC 5285 one byte extensions",
 	)
 	errRFC8285OneByteHeaderSize = errors.New(
-		"header extension payload must be 16bytes or less for RFC 5285 one byte extensions",
+		"header extension payload must be between 1 and 16 bytes for RFC 5285 one byte extensions",
 	)
 
 	errRFC8285TwoByteHeaderIDRange = errors.New(
@@ -29,6 +32,18 @@ var (
 	)
 
 	errRFC3550HeaderIDRange = errors.New("header extension id must be 0 for non-RFC 5285 extensions")
+	errRFC3550HeaderCount   = errors.New("non-RFC 5285 header extension must have exactly one extension")
+	errRFC3550HeaderSize    = errors.New("non-RFC 5285 header extension payload must be a multiple of 4 bytes")
 
-	errInvalidRTPPadding = errors.New("invalid RTP padding")
+	errTooManyCSRC         = errors.New("CSRC count exceeds 15")
+	errExtensionTooLong    = errors.New("header extension exceeds 65535 words")
+	errMarshalSizeMismatch = errors.New("marshaled header size does not match MarshalSize")
+
+	errInvalidRTPPadding  = errors.New("invalid RTP padding")
+	errMissingPaddingByte = errors.New("RTP padding bit set without a padding count byte")
+
+	errExceedsMTU = errors.New("packet exceeds MTU")
+
+	errPacketTooLargeForFraming = errors.New("packet too large for a 2-byte length prefix")
+	errTruncatedFrame           = errors.New("framed packet is truncated")
 )
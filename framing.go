@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// lengthPrefixSize is the size of the big-endian length preceding each packet
+// in the framing used by WriteLengthPrefixed and ReadLengthPrefixed, which is
+// the RTP over TCP framing of RFC 4571.
+const lengthPrefixSize = 2
+
+// MarshalFramed serializes the packet preceded by its marshaled size as a
+// 2-byte big-endian integer, as in RFC 4571.
+func MarshalFramed(p *Packet) ([]byte, error) {
+	size := p.MarshalSize()
+	if size > 0xFFFF {
+		return nil, fmt.Errorf("%w: %d", errPacketTooLargeForFraming, size)
+	}
+
+	buf := make([]byte, lengthPrefixSize+size)
+	binary.BigEndian.PutUint16(buf, uint16(size)) // nolint: gosec // G115
+	if _, err := p.MarshalTo(buf[lengthPrefixSize:]); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// WriteLengthPrefixed writes the packet to w framed like MarshalFramed, so a
+// stream of packets can be sent over TCP or stored, and read back with
+// ReadLengthPrefixed.
+func WriteLengthPrefixed(w io.Writer, p *Packet) error {
+	buf, err := MarshalFramed(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf)
+
+	return err
+}
+
+// ReadLengthPrefixed reads a packet written by WriteLengthPrefixed from r.
+// It returns io.EOF when r ends between packets, and io.ErrUnexpectedEOF
+// when it ends within one.
+func ReadLengthPrefixed(r io.Reader) (*Packet, error) {
+	var prefix [lengthPrefixSize]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.BigEndian.Uint16(prefix[:]))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	p := &Packet{}
+	if err := p.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UnmarshalFramed parses the packets of buf, each preceded by its length as a
+// 2-byte big-endian integer as in RFC 4571. The packets reference buf.
+func UnmarshalFramed(buf []byte) ([]*Packet, error) {
+	var packets []*Packet
+	for offset := 0; offset < len(buf); {
+		if len(buf)-offset < lengthPrefixSize {
+			return nil, fmt.Errorf("%w: length prefix at offset %d", errTruncatedFrame, offset)
+		}
+
+		size := int(binary.BigEndian.Uint16(buf[offset:]))
+		offset += lengthPrefixSize
+		if len(buf)-offset < size {
+			return nil, fmt.Errorf("%w: %d < %d at offset %d", errTruncatedFrame, len(buf)-offset, size, offset)
+		}
+
+		p := &Packet{}
+		if err := p.Unmarshal(buf[offset : offset+size]); err != nil {
+			return nil, err
+		}
+		packets = append(packets, p)
+		offset += size
+	}
+
+	return packets, nil
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLengthPrefixedRoundtrip(t *testing.T) {
+	packets := []*Packet{
+		{
+			Header: Header{
+				Version:        2,
+				Marker:         true,
+				PayloadType:    96,
+				SequenceNumber: 1,
+				Timestamp:      1000,
+				SSRC:           0x1234ABCD,
+				CSRC:           []uint32{},
+			},
+			Payload: []byte{0x01, 0x02, 0x03},
+		},
+		{
+			Header: Header{
+				Version:          2,
+				Extension:        true,
+				ExtensionProfile: extensionProfileOneByte,
+				Extensions:       []Extension{{id: 1, payload: []byte{0xAA}}},
+				rawExtension:     []byte{0x10, 0xAA, 0x00, 0x00},
+				PayloadType:      96,
+				SequenceNumber:   2,
+				Timestamp:        2000,
+				SSRC:             0x1234ABCD,
+				CSRC:             []uint32{},
+			},
+			Payload: []byte{0x04},
+		},
+	}
+
+	var stream bytes.Buffer
+	for _, p := range packets {
+		if err := WriteLengthPrefixed(&stream, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, expected := range packets {
+		p, err := ReadLengthPrefixed(&stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(p.Header, expected.Header) || !bytes.Equal(p.Payload, expected.Payload) {
+			t.Errorf("Packet %d: expected %v, got %v", i, expected, p)
+		}
+	}
+
+	if _, err := ReadLengthPrefixed(&stream); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestLengthPrefixedErrors(t *testing.T) {
+	if _, err := ReadLengthPrefixed(bytes.NewReader([]byte{0x00, 0x0C, 0x80})); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	if _, err := ReadLengthPrefixed(bytes.NewReader([]byte{0x00})); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	_, err := ReadLengthPrefixed(bytes.NewReader([]byte{0x00, 0x01, 0x80}))
+	if !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+
+	large := &Packet{Header: Header{Version: 2}, Payload: make([]byte, 0x10000)}
+	if err := WriteLengthPrefixed(&bytes.Buffer{}, large); !errors.Is(err, errPacketTooLargeForFraming) {
+		t.Errorf("Expected %v, got %v", errPacketTooLargeForFraming, err)
+	}
+}
+
+func TestUnmarshalFramed(t *testing.T) {
+	first := []byte{0x80, 0x60, 0x00, 0x01, 0x00, 0x00, 0x03, 0xE8, 0x12, 0x34, 0xAB, 0xCD, 0x01, 0x02}
+	second := []byte{0x80, 0xE0, 0x00, 0x02, 0x00, 0x00, 0x07, 0xD0, 0x12, 0x34, 0xAB, 0xCD, 0x03}
+
+	var buf []byte
+	buf = append(append(buf, 0x00, byte(len(first))), first...)
+	buf = append(append(buf, 0x00, byte(len(second))), second...)
+
+	packets, err := UnmarshalFramed(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("Expected 2 packets, got %d", len(packets))
+	}
+	if packets[0].SequenceNumber != 1 || !bytes.Equal(packets[0].Payload, []byte{0x01, 0x02}) {
+		t.Errorf("Unexpected first packet %v", packets[0])
+	}
+	if packets[1].SequenceNumber != 2 || !packets[1].Marker || !bytes.Equal(packets[1].Payload, []byte{0x03}) {
+		t.Errorf("Unexpected second packet %v", packets[1])
+	}
+
+	if packets, err = UnmarshalFramed(nil); err != nil || packets != nil {
+		t.Errorf("Expected no packets for an empty buffer, got %v, %v", packets, err)
+	}
+
+	for _, truncated := range [][]byte{buf[:len(buf)-1], buf[:len(first)+3]} {
+		if _, err = UnmarshalFramed(truncated); !errors.Is(err, errTruncatedFrame) {
+			t.Errorf("Expected %v, got %v", errTruncatedFrame, err)
+		}
+	}
+}
+
+func TestMarshalFramed(t *testing.T) {
+	packets := []*Packet{
+		{Header: Header{Version: 2, PayloadType: 96, SequenceNumber: 1, CSRC: []uint32{}}, Payload: []byte{0x01}},
+		{Header: Header{Version: 2, PayloadType: 96, SequenceNumber: 2, CSRC: []uint32{}}, Payload: []byte{0x02, 0x03}},
+	}
+
+	var buf []byte
+	for _, p := range packets {
+		framed, err := MarshalFramed(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size := int(framed[0])<<8 | int(framed[1]); size != p.MarshalSize() {
+			t.Errorf("Expected length prefix %d, got %d", p.MarshalSize(), size)
+		}
+		buf = append(buf, framed...)
+	}
+
+	parsed, err := UnmarshalFramed(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, packets) {
+		t.Errorf("Expected %v, got %v", packets, parsed)
+	}
+
+	large := &Packet{Header: Header{Version: 2}, Payload: make([]byte, 0x10000)}
+	if _, err = MarshalFramed(large); !errors.Is(err, errPacketTooLargeForFraming) {
+		t.Errorf("Expected %v, got %v", errPacketTooLargeForFraming, err)
+	}
+}
@@ -38,7 +38,7 @@ func (e *OneByteHeaderExtension) Set(id uint8, buf []byte) error {
 	if id < 1 || id > 14 {
 		return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderIDRange, id)
 	}
-	if len(buf) > 16 {
+	if len(buf) == 0 || len(buf) > 16 {
 		return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderSize, len(buf))
 	}
 
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"time"
+)
+
+// JitterEstimator computes the interarrival jitter of an RTP stream as
+// described in RFC 3550 Section 6.4.1 and Appendix A.8.
+type JitterEstimator struct {
+	clockRate uint32
+
+	initialized   bool
+	lastTimestamp uint32
+	lastArrival   time.Time
+	jitter        float64
+}
+
+// NewJitterEstimator returns a new JitterEstimator for a stream with the given clock rate.
+func NewJitterEstimator(clockRate uint32) *JitterEstimator {
+	return &JitterEstimator{
+		clockRate: clockRate,
+	}
+}
+
+// Update feeds the RTP timestamp and arrival time of a received packet into the estimator.
+func (j *JitterEstimator) Update(rtpTimestamp uint32, arrival time.Time) {
+	if !j.initialized {
+		j.initialized = true
+		j.lastTimestamp = rtpTimestamp
+		j.lastArrival = arrival
+
+		return
+	}
+
+	// D(i-1,i) = (Rj - Ri) - (Sj - Si), in timestamp units.
+	// The RTP timestamp difference is signed to handle wraparound and reordering.
+	arrivalDelta := arrival.Sub(j.lastArrival).Seconds() * float64(j.clockRate)
+	timestampDelta := float64(int32(rtpTimestamp - j.lastTimestamp)) // nolint: gosec // G115
+	d := arrivalDelta - timestampDelta
+	if d < 0 {
+		d = -d
+	}
+
+	// J(i) = J(i-1) + (|D(i-1,i)| - J(i-1))/16
+	j.jitter += (d - j.jitter) / 16
+
+	j.lastTimestamp = rtpTimestamp
+	j.lastArrival = arrival
+}
+
+// Jitter returns the current interarrival jitter estimate in timestamp units.
+func (j *JitterEstimator) Jitter() float64 {
+	return j.jitter
+}
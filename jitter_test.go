@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestJitterEstimator(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("NoJitter", func(t *testing.T) {
+		estimator := NewJitterEstimator(8000)
+		for i := 0; i < 10; i++ {
+			estimator.Update(uint32(160*i), start.Add(time.Duration(i)*20*time.Millisecond)) // nolint: gosec
+		}
+		if estimator.Jitter() != 0 {
+			t.Errorf("Expected zero jitter, got %f", estimator.Jitter())
+		}
+	})
+
+	t.Run("RFC3550Arithmetic", func(t *testing.T) {
+		estimator := NewJitterEstimator(8000)
+
+		// Packets are sent every 20ms (160 samples), arrivals are delayed by
+		// 0, 5, 0 and 10ms: D = 40, 40 and 80 timestamp units.
+		estimator.Update(0, start)
+		estimator.Update(160, start.Add(25*time.Millisecond))
+		estimator.Update(320, start.Add(40*time.Millisecond))
+		estimator.Update(480, start.Add(70*time.Millisecond))
+
+		expected := 0.0
+		for _, d := range []float64{40, 40, 80} {
+			expected += (d - expected) / 16
+		}
+		if math.Abs(estimator.Jitter()-expected) > 1e-9 {
+			t.Errorf("Expected jitter %f, got %f", expected, estimator.Jitter())
+		}
+	})
+
+	t.Run("TimestampWraparound", func(t *testing.T) {
+		estimator := NewJitterEstimator(8000)
+		estimator.Update(math.MaxUint32-79, start)
+		estimator.Update(80, start.Add(25*time.Millisecond))
+
+		if expected := 40.0 / 16; math.Abs(estimator.Jitter()-expected) > 1e-9 {
+			t.Errorf("Expected jitter %f, got %f", expected, estimator.Jitter())
+		}
+	})
+}
@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+// NewKeepAlivePacket returns a packet without payload, such as the ones sent
+// to keep NAT bindings open while a stream is paused. The packet marshals to
+// the 12 byte fixed header only, which Unmarshal accepts like any other packet.
+func NewKeepAlivePacket(pt uint8, ssrc uint32, seq uint16) *Packet {
+	return &Packet{
+		Header: Header{
+			Version:        2,
+			PayloadType:    pt,
+			SequenceNumber: seq,
+			SSRC:           ssrc,
+			CSRC:           []uint32{},
+		},
+		Payload: []byte{},
+	}
+}
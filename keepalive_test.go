@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewKeepAlivePacket(t *testing.T) {
+	packet := NewKeepAlivePacket(96, 0x1234ABCD, 1000)
+
+	raw, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != csrcOffset {
+		t.Fatalf("Expected a %d byte packet, got %d", csrcOffset, len(raw))
+	}
+
+	parsed := &Packet{}
+	if err = parsed.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, packet) {
+		t.Errorf("Expected %v, got %v", packet, parsed)
+	}
+}
@@ -5,8 +5,10 @@ package rtp
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 )
 
 // Extension RTP Header extension.
@@ -16,6 +18,10 @@ type Extension struct {
 }
 
 // Header represents an RTP packet header.
+//
+// Unmarshal reuses the backing array of CSRC when it is large enough, so a
+// CSRC slice retained by the caller is overwritten by the next Unmarshal into
+// the same Header. Use CSRCCopy to keep the list.
 type Header struct {
 	Version          uint8
 	Padding          bool
@@ -29,8 +35,50 @@ type Header struct {
 	ExtensionProfile uint16
 	Extensions       []Extension
 
+	// ExtensionPaddingByte is the value of the bytes Marshal adds to align the
+	// header extension to 4 bytes. RFC 8285 requires zero, the default, so other
+	// values only suit receivers expecting them.
+	ExtensionPaddingByte byte
+
+	// TolerantLayout makes Unmarshal accept packets from broken senders
+	// placing the header extension before the CSRC list, when the header
+	// can't be parsed with the standard layout.
+	TolerantLayout bool
+
+	// ContinuePastReservedExtID makes Unmarshal skip the reserved one-byte
+	// extension ID 15 and parse the following bytes as extensions. By default,
+	// as RFC 8285 requires, processing of the extensions stops there.
+	ContinuePastReservedExtID bool
+
+	// MaxExtensionBytes limits the size of the header extension accepted by
+	// Unmarshal, excluding the 4 byte extension header. Zero means unlimited.
+	MaxExtensionBytes int
+	// MaxExtensionElements limits the number of RFC 8285 extension elements
+	// accepted by Unmarshal. Zero means unlimited.
+	MaxExtensionElements int
+
+	// RejectDuplicateExtensionIDs makes Unmarshal return an error when an RFC 8285
+	// extension ID appears more than once, which RFC 8285 forbids. By default
+	// duplicates are kept, and GetExtension returns the first one.
+	RejectDuplicateExtensionIDs bool
+
+	// ExtensionLengthInBytes makes Unmarshal read the length of the header
+	// extension as a number of bytes, for broken senders writing it so, instead
+	// of a number of 32-bit words as RFC 3550 requires. Marshal is unaffected.
+	ExtensionLengthInBytes bool
+
+	// ParseCryptexExtensions makes Unmarshal parse the extension of the RFC 9335
+	// cryptex profiles as RFC 8285 elements, for headers whose extension was
+	// already decrypted. By default it is encrypted, and kept as a single opaque
+	// element with ID 0 like RFC 3550 extensions.
+	ParseCryptexExtensions bool
+
 	// Deprecated: will be removed in a future version.
 	PayloadOffset int
+
+	// rawExtension is the extension data read by Unmarshal, without the 4 bytes
+	// holding the profile and length. It is cleared when the extensions change.
+	rawExtension []byte
 }
 
 // Packet represents an RTP Packet.
@@ -41,6 +89,23 @@ type Packet struct {
 
 	// Deprecated: will be removed in a future version.
 	Raw []byte
+
+	// PopulateRaw makes Unmarshal set Raw to the whole parsed buffer, easing
+	// the migration of code depending on it.
+	//
+	// Deprecated: will be removed along with Raw.
+	PopulateRaw bool
+
+	// KeepPadding makes Unmarshal leave the padding at the end of Payload, while
+	// still setting PaddingSize. Marshaling then writes Payload as is, without
+	// appending PaddingSize bytes. PayloadWithoutPadding returns the payload
+	// without padding in either mode.
+	KeepPadding bool
+
+	// storage owned by the packet, used by UnmarshalCopy
+	buf []byte
+	// marshal buffer reused by WriteTo
+	writeBuf []byte
 }
 
 const (
@@ -68,6 +133,12 @@ const (
 	csrcLength              = 4
 )
 
+// RFC 9335 cryptex profiles, laid out like their RFC 8285 counterparts once decrypted.
+const (
+	extensionProfileCryptexOneByte = 0xC0DE
+	extensionProfileCryptexTwoByte = 0xC2DE
+)
+
 // String helps with debugging by printing packet information in a readable way.
 func (p Packet) String() string {
 	out := "RTP PACKET:\n"
@@ -78,14 +149,98 @@ func (p Packet) String() string {
 	out += fmt.Sprintf("\tSequence Number: %d\n", p.SequenceNumber)
 	out += fmt.Sprintf("\tTimestamp: %d\n", p.Timestamp)
 	out += fmt.Sprintf("\tSSRC: %d (%x)\n", p.SSRC, p.SSRC)
+	out += p.Header.csrcAndExtensionsString()
 	out += fmt.Sprintf("\tPayload Length: %d\n", len(p.Payload))
 
 	return out
 }
 
+// String helps with debugging by printing header information in a readable way.
+func (h Header) String() string {
+	out := "RTP HEADER:\n"
+
+	out += fmt.Sprintf("\tVersion: %v\n", h.Version)
+	out += fmt.Sprintf("\tMarker: %v\n", h.Marker)
+	out += fmt.Sprintf("\tPayload Type: %d\n", h.PayloadType)
+	out += fmt.Sprintf("\tSequence Number: %d\n", h.SequenceNumber)
+	out += fmt.Sprintf("\tTimestamp: %d\n", h.Timestamp)
+	out += fmt.Sprintf("\tSSRC: %d (%x)\n", h.SSRC, h.SSRC)
+	out += h.csrcAndExtensionsString()
+
+	return out
+}
+
+// csrcAndExtensionsString prints the CSRC list and the header extensions, if any.
+func (h Header) csrcAndExtensionsString() string {
+	out := ""
+
+	if len(h.CSRC) > 0 {
+		out += fmt.Sprintf("\tCSRC: %v\n", h.CSRC)
+	}
+
+	if h.Extension {
+		out += fmt.Sprintf("\tExtension Profile: %#04x\n", h.ExtensionProfile)
+		for _, extension := range h.Extensions {
+			out += fmt.Sprintf("\tExtension ID %d: %x\n", extension.id, extension.payload)
+		}
+	}
+
+	return out
+}
+
 // Unmarshal parses the passed byte slice and stores the result in the Header.
 // It returns the number of bytes read n and any error.
-func (h *Header) Unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cyclop
+func (h *Header) Unmarshal(buf []byte) (n int, err error) {
+	n, err = h.unmarshal(buf)
+	if err != nil && h.TolerantLayout && errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		if reordered := h.reorderExtensionBeforeCSRC(buf); reordered != nil {
+			if m, retryErr := h.unmarshal(reordered); retryErr == nil {
+				return m, nil
+			}
+			// report the error of the standard layout
+			n, err = h.unmarshal(buf)
+		}
+	}
+
+	return n, err
+}
+
+// reorderExtensionBeforeCSRC returns a copy of buf with the header extension
+// found right after the fixed header moved after the CSRC list, or nil if buf
+// doesn't have that layout.
+func (h *Header) reorderExtensionBeforeCSRC(buf []byte) []byte {
+	nCSRC := int(buf[0] & ccMask)
+	extensionStart := csrcOffset
+	if nCSRC == 0 || len(buf) < extensionStart+4 {
+		return nil
+	}
+
+	extensionEnd := extensionStart + 4 + h.extensionLength(buf[extensionStart+2:])
+	csrcEnd := extensionEnd + nCSRC*csrcLength
+	if len(buf) < csrcEnd {
+		return nil
+	}
+
+	reordered := make([]byte, 0, len(buf))
+	reordered = append(reordered, buf[:csrcOffset]...)
+	reordered = append(reordered, buf[extensionEnd:csrcEnd]...)
+	reordered = append(reordered, buf[extensionStart:extensionEnd]...)
+
+	return append(reordered, buf[csrcEnd:]...)
+}
+
+// extensionLength returns the size in bytes of the header extension whose
+// length field starts buf, honoring ExtensionLengthInBytes.
+func (h *Header) extensionLength(buf []byte) int {
+	length := int(binary.BigEndian.Uint16(buf))
+	if !h.ExtensionLengthInBytes {
+		length *= 4
+	}
+
+	return length
+}
+
+func (h *Header) unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cyclop
 	if len(buf) < headerLength {
 		return 0, fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), headerLength)
 	}
@@ -136,6 +291,7 @@ func (h *Header) Unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cy
 	if h.Extensions != nil {
 		h.Extensions = h.Extensions[:0]
 	}
+	h.rawExtension = nil
 
 	if h.Extension { // nolint: nestif
 		if expected := n + 4; len(buf) < expected {
@@ -147,15 +303,24 @@ func (h *Header) Unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cy
 
 		h.ExtensionProfile = binary.BigEndian.Uint16(buf[n:])
 		n += 2
-		extensionLength := int(binary.BigEndian.Uint16(buf[n:])) * 4
+		extensionLength := h.extensionLength(buf[n:])
 		n += 2
 		extensionEnd := n + extensionLength
 
+		if h.MaxExtensionBytes > 0 && extensionLength > h.MaxExtensionBytes {
+			return n, fmt.Errorf("%w: %d > %d", errExtensionTooLarge, extensionLength, h.MaxExtensionBytes)
+		}
+
 		if len(buf) < extensionEnd {
 			return n, fmt.Errorf("size %d < %d: %w", len(buf), extensionEnd, errHeaderSizeInsufficientForExtension)
 		}
+		rawExtension := buf[n:extensionEnd]
 
-		if h.ExtensionProfile == extensionProfileOneByte || h.ExtensionProfile == extensionProfileTwoByte {
+		profile := h.ExtensionProfile
+		if h.ParseCryptexExtensions {
+			profile = baseExtensionProfile(profile)
+		}
+		if profile == extensionProfileOneByte || profile == extensionProfileTwoByte {
 			var (
 				extid      uint8
 				payloadLen int
@@ -168,28 +333,45 @@ func (h *Header) Unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cy
 					continue
 				}
 
-				if h.ExtensionProfile == extensionProfileOneByte {
+				if profile == extensionProfileOneByte {
 					extid = buf[n] >> 4
 					payloadLen = int(buf[n]&^0xF0 + 1)
 					n++
 
 					if extid == extensionIDReserved {
+						if h.ContinuePastReservedExtID {
+							continue
+						}
+
 						break
 					}
 				} else {
 					extid = buf[n]
 					n++
 
-					if len(buf) <= n {
-						return n, fmt.Errorf("size %d < %d: %w", len(buf), n, errHeaderSizeInsufficientForExtension)
+					if n >= extensionEnd {
+						return n, fmt.Errorf("size %d < %d: %w", extensionEnd, n+1, errHeaderSizeInsufficientForExtension)
 					}
 
 					payloadLen = int(buf[n])
 					n++
 				}
 
-				if extensionPayloadEnd := n + payloadLen; len(buf) <= extensionPayloadEnd {
-					return n, fmt.Errorf("size %d < %d: %w", len(buf), extensionPayloadEnd, errHeaderSizeInsufficientForExtension)
+				// Elements must end within the extension, never in the payload
+				if extensionPayloadEnd := n + payloadLen; extensionPayloadEnd > extensionEnd {
+					return n, fmt.Errorf("size %d < %d: %w", extensionEnd, extensionPayloadEnd, errHeaderSizeInsufficientForExtension)
+				}
+
+				if h.MaxExtensionElements > 0 && len(h.Extensions) >= h.MaxExtensionElements {
+					return n, fmt.Errorf("%w: more than %d", errTooManyExtensions, h.MaxExtensionElements)
+				}
+
+				if h.RejectDuplicateExtensionIDs {
+					for _, extension := range h.Extensions {
+						if extension.id == extid {
+							return n, fmt.Errorf("%w: %d", errDuplicateExtensionID, extid)
+						}
+					}
 				}
 
 				extension := Extension{id: extid, payload: buf[n : n+payloadLen]}
@@ -202,12 +384,43 @@ func (h *Header) Unmarshal(buf []byte) (n int, err error) { //nolint:gocognit,cy
 			h.Extensions = append(h.Extensions, extension)
 			n += len(h.Extensions[0].payload)
 		}
+
+		h.rawExtension = rawExtension
 	}
 
 	return n, nil
 }
 
+// ParseRoutingInfo reads the payload type, marker bit, sequence number, timestamp
+// and SSRC from the fixed part of a raw RTP header. CSRCs and extensions are not
+// parsed, which makes it considerably cheaper than a full Unmarshal.
+func ParseRoutingInfo(buf []byte) (pt uint8, marker bool, seq uint16, ts uint32, ssrc uint32, err error) {
+	if len(buf) < csrcOffset {
+		return 0, false, 0, 0, 0, fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), csrcOffset)
+	}
+
+	pt = buf[1] & ptMask
+	marker = (buf[1] >> markerShift & markerMask) > 0
+	seq = binary.BigEndian.Uint16(buf[seqNumOffset : seqNumOffset+seqNumLength])
+	ts = binary.BigEndian.Uint32(buf[timestampOffset : timestampOffset+timestampLength])
+	ssrc = binary.BigEndian.Uint32(buf[ssrcOffset : ssrcOffset+ssrcLength])
+
+	return pt, marker, seq, ts, ssrc, nil
+}
+
+// PayloadType reads the payload type from a raw RTP header, ignoring the
+// marker bit. Only the first two bytes of buf are required.
+func PayloadType(buf []byte) (uint8, error) {
+	if len(buf) < 2 {
+		return 0, fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), 2)
+	}
+
+	return buf[1] & ptMask, nil
+}
+
 // Unmarshal parses the passed byte slice and stores the result in the Packet.
+// An RTP packet doesn't carry its length, so buf is consumed whole; packets
+// coalesced in a stream are parsed by UnmarshalFramed or ReadLengthPrefixed.
 func (p *Packet) Unmarshal(buf []byte) error {
 	n, err := p.Header.Unmarshal(buf)
 	if err != nil {
@@ -217,22 +430,42 @@ func (p *Packet) Unmarshal(buf []byte) error {
 	end := len(buf)
 	if p.Header.Padding {
 		if end <= n {
-			return errTooSmall
+			return errMissingPaddingByte
 		}
 		p.PaddingSize = buf[end-1]
-		end -= int(p.PaddingSize)
+		if end-int(p.PaddingSize) < n {
+			return errTooSmall
+		}
+		if !p.KeepPadding {
+			end -= int(p.PaddingSize)
+		}
 	} else {
 		p.PaddingSize = 0
 	}
-	if end < n {
-		return errTooSmall
-	}
 
 	p.Payload = buf[n:end]
 
+	if p.PopulateRaw {
+		p.Raw = buf
+	}
+
 	return nil
 }
 
+// UnmarshalCopy parses the passed byte slice like Unmarshal, but copies it into
+// storage owned by the packet so that the payload and extension payloads don't
+// alias buf. The storage is reused across calls, invalidating the payloads of
+// the previous call.
+func (p *Packet) UnmarshalCopy(buf []byte) error {
+	if cap(p.buf) < len(buf) {
+		p.buf = make([]byte, len(buf))
+	}
+	p.buf = p.buf[:len(buf)]
+	copy(p.buf, buf)
+
+	return p.Unmarshal(p.buf)
+}
+
 // Marshal serializes the header into bytes.
 func (h Header) Marshal() (buf []byte, err error) {
 	buf = make([]byte, h.MarshalSize())
@@ -245,7 +478,35 @@ func (h Header) Marshal() (buf []byte, err error) {
 	return buf[:n], nil
 }
 
+// AppendMarshal appends the serialized header to dst and returns the extended
+// buffer. On error, dst is returned unchanged.
+func (h Header) AppendMarshal(dst []byte) ([]byte, error) {
+	start := len(dst)
+	buf := grow(dst, h.MarshalSize())
+
+	n, err := h.MarshalTo(buf[start:])
+	if err != nil {
+		return dst, err
+	}
+
+	return buf[:start+n], nil
+}
+
+// grow extends the length of buf by n bytes, reallocating it if its capacity is insufficient.
+func grow(buf []byte, n int) []byte {
+	if cap(buf)-len(buf) < n {
+		grown := make([]byte, len(buf), len(buf)+n)
+		copy(grown, buf)
+		buf = grown
+	}
+
+	return buf[:len(buf)+n]
+}
+
 // MarshalTo serializes the header and writes to the buffer.
+// The returned n, which always equals MarshalSize, is the offset where the
+// payload goes, so callers such as SRTP can write or encrypt it directly into
+// buf[n:] without a second pass.
 func (h Header) MarshalTo(buf []byte) (n int, err error) { //nolint:cyclop
 	/*
 	 *  0                   1                   2                   3
@@ -300,10 +561,14 @@ func (h Header) MarshalTo(buf []byte) (n int, err error) { //nolint:cyclop
 		n += 4
 		startExtensionsPos := n
 
-		switch h.ExtensionProfile {
+		switch h.extensionLayout() {
 		// RFC 8285 RTP One Byte Header Extension
 		case extensionProfileOneByte:
 			for _, extension := range h.Extensions {
+				if len(extension.payload) == 0 || len(extension.payload) > 16 {
+					// The length is encoded minus one in 4 bits, an empty payload would underflow
+					return 0, fmt.Errorf("%w: %d bytes", errRFC8285OneByteHeaderSize, len(extension.payload))
+				}
 				buf[n] = extension.id<<4 | (uint8(len(extension.payload)) - 1) // nolint: gosec // G115
 				n++
 				n += copy(buf[n:], extension.payload)
@@ -311,6 +576,10 @@ func (h Header) MarshalTo(buf []byte) (n int, err error) { //nolint:cyclop
 		// RFC 8285 RTP Two Byte Header Extension
 		case extensionProfileTwoByte:
 			for _, extension := range h.Extensions {
+				if len(extension.payload) > 255 {
+					// The length wouldn't fit in its byte
+					return 0, fmt.Errorf("%w: %d bytes", errRFC8285TwoByteHeaderSize, len(extension.payload))
+				}
 				buf[n] = extension.id
 				n++
 				buf[n] = uint8(len(extension.payload)) // nolint: gosec // G115
@@ -335,7 +604,7 @@ func (h Header) MarshalTo(buf []byte) (n int, err error) { //nolint:cyclop
 
 		// add padding to reach 4 bytes boundaries
 		for i := 0; i < roundedExtSize-extSize; i++ {
-			buf[n] = 0
+			buf[n] = h.ExtensionPaddingByte
 			n++
 		}
 	}
@@ -343,6 +612,111 @@ func (h Header) MarshalTo(buf []byte) (n int, err error) { //nolint:cyclop
 	return n, nil
 }
 
+// ExtensionInfo returns the extension profile, the number of extension
+// elements and the extension data, including the padding to a multiple of 4
+// bytes but not the 4 bytes holding the profile and length. rawBlock is the
+// data read by Unmarshal, unless the extensions were changed since, in which
+// case it is marshaled. It returns zero values when the extension bit is
+// unset, and a nil rawBlock if the header can't be marshaled.
+func (h Header) ExtensionInfo() (profile uint16, numElements int, rawBlock []byte) {
+	if !h.Extension {
+		return 0, 0, nil
+	}
+
+	if h.rawExtension != nil {
+		return h.ExtensionProfile, len(h.Extensions), h.rawExtension
+	}
+
+	buf, err := h.Marshal()
+	if err != nil {
+		return h.ExtensionProfile, len(h.Extensions), nil
+	}
+
+	return h.ExtensionProfile, len(h.Extensions), buf[csrcOffset+len(h.CSRC)*csrcLength+4:]
+}
+
+// PayloadBudget returns the number of payload bytes that fit in a packet of
+// mtu bytes with this header, including its CSRCs and extensions. RTP padding,
+// if any, has to be subtracted by the caller. It returns 0 if the header alone
+// doesn't fit.
+func (h Header) PayloadBudget(mtu int) int {
+	budget := mtu - h.MarshalSize()
+	if budget < 0 {
+		return 0
+	}
+
+	return budget
+}
+
+// CacheKey returns a key identifying the packet by its SSRC and sequence
+// number, for deduplication caches. The SSRC is in the upper bits, above the
+// 16-bit sequence number, so distinct pairs always have distinct keys.
+func (h Header) CacheKey() uint64 {
+	return uint64(h.SSRC)<<16 | uint64(h.SequenceNumber)
+}
+
+// Validate checks that the header can be marshaled, returning a descriptive
+// error where MarshalSize or MarshalTo would otherwise panic or produce a
+// corrupt header, such as when Extension is set without any Extensions and
+// an ExtensionProfile other than the RFC 8285 ones.
+func (h Header) Validate() error { //nolint:cyclop
+	if len(h.CSRC) > 15 {
+		return fmt.Errorf("%w: %d", errTooManyCSRC, len(h.CSRC))
+	}
+
+	if h.Extension {
+		for _, extension := range h.Extensions {
+			switch h.extensionLayout() {
+			case extensionProfileOneByte:
+				if extension.id < 1 || extension.id > 14 {
+					return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderIDRange, extension.id)
+				}
+				if len(extension.payload) == 0 || len(extension.payload) > 16 {
+					return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderSize, len(extension.payload))
+				}
+			case extensionProfileTwoByte:
+				if extension.id < 1 {
+					return fmt.Errorf("%w actual(%d)", errRFC8285TwoByteHeaderIDRange, extension.id)
+				}
+				if len(extension.payload) > 255 {
+					return fmt.Errorf("%w actual(%d)", errRFC8285TwoByteHeaderSize, len(extension.payload))
+				}
+			default:
+				if extension.id != 0 {
+					return fmt.Errorf("%w actual(%d)", errRFC3550HeaderIDRange, extension.id)
+				}
+				if len(extension.payload)%4 != 0 {
+					return fmt.Errorf("%w actual(%d)", errRFC3550HeaderSize, len(extension.payload))
+				}
+			}
+		}
+
+		switch h.extensionLayout() {
+		case extensionProfileOneByte, extensionProfileTwoByte:
+		default:
+			if len(h.Extensions) != 1 {
+				return fmt.Errorf("%w actual(%d)", errRFC3550HeaderCount, len(h.Extensions))
+			}
+		}
+
+		if extSize := h.MarshalSize() - 12 - len(h.CSRC)*csrcLength - 4; extSize/4 > math.MaxUint16 {
+			return fmt.Errorf("%w: %d bytes", errExtensionTooLong, extSize)
+		}
+	}
+
+	// Make sure MarshalTo writes exactly MarshalSize bytes
+	size := h.MarshalSize()
+	n, err := h.MarshalTo(make([]byte, size))
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("%w: %d != %d", errMarshalSizeMismatch, n, size)
+	}
+
+	return nil
+}
+
 // MarshalSize returns the size of the header once marshaled.
 func (h Header) MarshalSize() int {
 	// NOTE: Be careful to match the MarshalTo() method.
@@ -351,7 +725,7 @@ func (h Header) MarshalSize() int {
 	if h.Extension {
 		extSize := 4
 
-		switch h.ExtensionProfile {
+		switch h.extensionLayout() {
 		// RFC 8285 RTP One Byte Header Extension
 		case extensionProfileOneByte:
 			for _, extension := range h.Extensions {
@@ -374,15 +748,17 @@ func (h Header) MarshalSize() int {
 }
 
 // SetExtension sets an RTP header extension.
+// RFC 8285 one byte extensions must carry between 1 and 16 bytes of payload,
+// empty payloads are only allowed with the two byte profile.
 func (h *Header) SetExtension(id uint8, payload []byte) error { //nolint:gocognit, cyclop
 	if h.Extension { // nolint: nestif
-		switch h.ExtensionProfile {
+		switch baseExtensionProfile(h.ExtensionProfile) {
 		// RFC 8285 RTP One Byte Header Extension
 		case extensionProfileOneByte:
 			if id < 1 || id > 14 {
 				return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderIDRange, id)
 			}
-			if len(payload) > 16 {
+			if len(payload) == 0 || len(payload) > 16 {
 				return fmt.Errorf("%w actual(%d)", errRFC8285OneByteHeaderSize, len(payload))
 			}
 		// RFC 8285 RTP Two Byte Header Extension
@@ -399,6 +775,8 @@ func (h *Header) SetExtension(id uint8, payload []byte) error { //nolint:gocogni
 			}
 		}
 
+		h.rawExtension = nil
+
 		// Update existing if it exists else add new extension
 		for i, extension := range h.Extensions {
 			if extension.id == id {
@@ -415,11 +793,13 @@ func (h *Header) SetExtension(id uint8, payload []byte) error { //nolint:gocogni
 
 	// No existing header extensions
 	h.Extension = true
+	h.rawExtension = nil
 
+	// One byte extensions can't carry an empty payload, as L=0 means one byte of data
 	switch payloadLen := len(payload); {
-	case payloadLen <= 16:
+	case payloadLen > 0 && payloadLen <= 16:
 		h.ExtensionProfile = extensionProfileOneByte
-	case payloadLen > 16 && payloadLen < 256:
+	case payloadLen == 0, payloadLen > 16 && payloadLen < 256:
 		h.ExtensionProfile = extensionProfileTwoByte
 	}
 
@@ -428,6 +808,143 @@ func (h *Header) SetExtension(id uint8, payload []byte) error { //nolint:gocogni
 	return nil
 }
 
+// MergeExtensions adds the extensions from other to the header. Extensions
+// already present are updated only when overwrite is set. RFC 8285 one byte
+// headers are promoted to two byte headers when an extension requires it.
+// The header is left unchanged if any extension is invalid for its profile.
+func (h *Header) MergeExtensions(other []Extension, overwrite bool) error { //nolint:cyclop
+	profile := h.ExtensionProfile
+	if !h.Extension {
+		profile = extensionProfileOneByte
+	}
+
+	for _, extension := range other {
+		if !overwrite && h.HasExtension(extension.id) {
+			continue
+		}
+
+		switch baseExtensionProfile(profile) {
+		case extensionProfileOneByte:
+			if isOneByteExtension(extension) {
+				continue
+			}
+			profile = twoByteExtensionProfile(profile)
+
+			fallthrough
+		case extensionProfileTwoByte:
+			if extension.id < 1 {
+				return fmt.Errorf("%w actual(%d)", errRFC8285TwoByteHeaderIDRange, extension.id)
+			}
+			if len(extension.payload) > 255 {
+				return fmt.Errorf("%w actual(%d)", errRFC8285TwoByteHeaderSize, len(extension.payload))
+			}
+		default: // RFC3550 Extension
+			if extension.id != 0 {
+				return fmt.Errorf("%w actual(%d)", errRFC3550HeaderIDRange, extension.id)
+			}
+		}
+	}
+
+	if len(other) == 0 {
+		return nil
+	}
+
+	if !h.Extension {
+		h.Extension = true
+		h.Extensions = nil
+	}
+	h.ExtensionProfile = profile
+	h.rawExtension = nil
+
+	for _, extension := range other {
+		if i := h.extensionIndex(extension.id); i >= 0 {
+			if overwrite {
+				h.Extensions[i].payload = extension.payload
+			}
+
+			continue
+		}
+
+		h.Extensions = append(h.Extensions, extension)
+	}
+
+	return nil
+}
+
+// OptimizeExtensionProfile switches the header from the RFC 8285 two byte
+// profile to the one byte profile when all extensions fit in one byte headers,
+// saving a byte per extension. Other profiles are left unchanged.
+func (h *Header) OptimizeExtensionProfile() {
+	if !h.Extension || baseExtensionProfile(h.ExtensionProfile) != extensionProfileTwoByte {
+		return
+	}
+
+	for _, extension := range h.Extensions {
+		if !isOneByteExtension(extension) {
+			return
+		}
+	}
+
+	if h.ExtensionProfile == extensionProfileCryptexTwoByte {
+		h.ExtensionProfile = extensionProfileCryptexOneByte
+	} else {
+		h.ExtensionProfile = extensionProfileOneByte
+	}
+	h.rawExtension = nil
+}
+
+// baseExtensionProfile returns the RFC 8285 profile matching the layout of a
+// cryptex profile, or profile itself for other profiles.
+func baseExtensionProfile(profile uint16) uint16 {
+	switch profile {
+	case extensionProfileCryptexOneByte:
+		return extensionProfileOneByte
+	case extensionProfileCryptexTwoByte:
+		return extensionProfileTwoByte
+	default:
+		return profile
+	}
+}
+
+// extensionLayout returns the profile whose layout the extension is marshaled
+// with. Cryptex profiles use the layout of their RFC 8285 counterparts, unless
+// the header holds their still encrypted extension as a single opaque element
+// with ID 0, as Unmarshal parses it by default.
+func (h Header) extensionLayout() uint16 {
+	profile := baseExtensionProfile(h.ExtensionProfile)
+	if profile != h.ExtensionProfile && len(h.Extensions) == 1 && h.Extensions[0].id == 0 {
+		return h.ExtensionProfile
+	}
+
+	return profile
+}
+
+// twoByteExtensionProfile returns the two byte profile a one byte profile is promoted to.
+func twoByteExtensionProfile(profile uint16) uint16 {
+	if profile == extensionProfileCryptexOneByte {
+		return extensionProfileCryptexTwoByte
+	}
+
+	return extensionProfileTwoByte
+}
+
+func isOneByteExtension(extension Extension) bool {
+	return extension.id >= 1 && extension.id <= 14 && len(extension.payload) >= 1 && len(extension.payload) <= 16
+}
+
+func (h *Header) extensionIndex(id uint8) int {
+	if !h.Extension {
+		return -1
+	}
+	for i, extension := range h.Extensions {
+		if extension.id == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // GetExtensionIDs returns an extension id array.
 func (h *Header) GetExtensionIDs() []uint8 {
 	if !h.Extension {
@@ -446,6 +963,39 @@ func (h *Header) GetExtensionIDs() []uint8 {
 	return ids
 }
 
+// NumExtensions returns the number of extensions in the header, or 0 if
+// extensions are disabled.
+func (h *Header) NumExtensions() int {
+	if !h.Extension {
+		return 0
+	}
+
+	return len(h.Extensions)
+}
+
+// MID returns the value of the RTP Stream Identifier Source Description
+// extension carrying the media identification (RFC 9143) with the given id.
+// ok is false if the extension is absent.
+func (h *Header) MID(id uint8) (mid string, ok bool) {
+	return h.stringExtension(id)
+}
+
+// RID returns the value of the RTP Stream Identifier Source Description
+// extension carrying the RtpStreamId (RFC 8852) with the given id.
+// ok is false if the extension is absent.
+func (h *Header) RID(id uint8) (rid string, ok bool) {
+	return h.stringExtension(id)
+}
+
+func (h *Header) stringExtension(id uint8) (string, bool) {
+	i := h.extensionIndex(id)
+	if i < 0 {
+		return "", false
+	}
+
+	return string(h.Extensions[i].payload), true
+}
+
 // GetExtension returns an RTP header extension.
 func (h *Header) GetExtension(id uint8) []byte {
 	if !h.Extension {
@@ -460,6 +1010,11 @@ func (h *Header) GetExtension(id uint8) []byte {
 	return nil
 }
 
+// HasExtension reports whether the header contains an RTP header extension with the given id.
+func (h *Header) HasExtension(id uint8) bool {
+	return h.extensionIndex(id) >= 0
+}
+
 // DelExtension Removes an RTP Header extension.
 func (h *Header) DelExtension(id uint8) error {
 	if !h.Extension {
@@ -468,6 +1023,7 @@ func (h *Header) DelExtension(id uint8) error {
 	for i, extension := range h.Extensions {
 		if extension.id == id {
 			h.Extensions = append(h.Extensions[:i], h.Extensions[i+1:]...)
+			h.rawExtension = nil
 
 			return nil
 		}
@@ -476,6 +1032,28 @@ func (h *Header) DelExtension(id uint8) error {
 	return errHeaderExtensionNotFound
 }
 
+// DelExtensionFunc removes the extensions for which del returns true and
+// returns how many were removed. The order of the remaining extensions is kept.
+func (h *Header) DelExtensionFunc(del func(id uint8, payload []byte) bool) int {
+	if !h.Extension {
+		return 0
+	}
+
+	kept := h.Extensions[:0]
+	for _, extension := range h.Extensions {
+		if !del(extension.id, extension.payload) {
+			kept = append(kept, extension)
+		}
+	}
+	removed := len(h.Extensions) - len(kept)
+	h.Extensions = kept
+	if removed > 0 {
+		h.rawExtension = nil
+	}
+
+	return removed
+}
+
 // Marshal serializes the packet into bytes.
 func (p Packet) Marshal() (buf []byte, err error) {
 	buf = make([]byte, p.MarshalSize())
@@ -488,6 +1066,54 @@ func (p Packet) Marshal() (buf []byte, err error) {
 	return buf[:n], nil
 }
 
+// AppendMarshal appends the serialized packet to dst and returns the extended
+// buffer. On error, dst is returned unchanged.
+func (p Packet) AppendMarshal(dst []byte) ([]byte, error) {
+	start := len(dst)
+	buf := grow(dst, p.MarshalSize())
+
+	n, err := p.MarshalTo(buf[start:])
+	if err != nil {
+		return dst, err
+	}
+
+	return buf[:start+n], nil
+}
+
+// MarshalWithMTU serializes the packet into bytes, failing if the serialized
+// packet would be larger than mtu.
+func (p Packet) MarshalWithMTU(mtu int) (buf []byte, err error) {
+	if size := p.MarshalSize(); size > mtu {
+		return nil, fmt.Errorf("%w: %d > %d", errExceedsMTU, size, mtu)
+	}
+
+	return p.Marshal()
+}
+
+// WriteTo serializes the packet and writes it to w, implementing io.WriterTo.
+// The serialization buffer is kept by the packet and reused across calls.
+func (p *Packet) WriteTo(w io.Writer) (int64, error) {
+	size := p.MarshalSize()
+	if cap(p.writeBuf) < size {
+		p.writeBuf = make([]byte, size)
+	}
+	buf := p.writeBuf[:size]
+
+	// MarshalTo only sets the padding count byte, so clear the reused padding
+	for i := size - p.paddingSize(); i < size; i++ {
+		buf[i] = 0
+	}
+
+	n, err := p.MarshalTo(buf)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := w.Write(buf[:n])
+
+	return int64(written), err
+}
+
 // MarshalTo serializes the packet and writes to the buffer.
 func (p *Packet) MarshalTo(buf []byte) (n int, err error) {
 	if p.Header.Padding && p.PaddingSize == 0 {
@@ -500,22 +1126,76 @@ func (p *Packet) MarshalTo(buf []byte) (n int, err error) {
 	}
 
 	// Make sure the buffer is large enough to hold the packet.
-	if n+len(p.Payload)+int(p.PaddingSize) > len(buf) {
+	if n+len(p.Payload)+p.paddingSize() > len(buf) {
 		return 0, io.ErrShortBuffer
 	}
 
 	m := copy(buf[n:], p.Payload)
 
-	if p.Header.Padding {
+	if p.Header.Padding && !p.KeepPadding {
 		buf[n+m+int(p.PaddingSize-1)] = p.PaddingSize
 	}
 
-	return n + m + int(p.PaddingSize), nil
+	return n + m + p.paddingSize(), nil
 }
 
 // MarshalSize returns the size of the packet once marshaled.
 func (p Packet) MarshalSize() int {
-	return p.Header.MarshalSize() + len(p.Payload) + int(p.PaddingSize)
+	return p.Header.MarshalSize() + len(p.Payload) + p.paddingSize()
+}
+
+// paddingSize returns the number of padding bytes appended after Payload
+// when marshaling, which is zero when KeepPadding left them in Payload.
+func (p *Packet) paddingSize() int {
+	if p.KeepPadding {
+		return 0
+	}
+
+	return int(p.PaddingSize)
+}
+
+// ValidatePadding checks that the padding fields of p are consistent, so that
+// callers get a descriptive error before Marshal. The padding bit requires a
+// non-zero PaddingSize, and a PaddingSize requires the padding bit. With
+// KeepPadding, Payload must also end with the PaddingSize padding bytes.
+func (p *Packet) ValidatePadding() error {
+	switch {
+	case p.Header.Padding && p.PaddingSize == 0:
+		return fmt.Errorf("%w: padding bit set with a zero PaddingSize", errInvalidRTPPadding)
+	case !p.Header.Padding && p.paddingSize() != 0:
+		return fmt.Errorf("%w: PaddingSize %d without the padding bit", errInvalidRTPPadding, p.PaddingSize)
+	case !p.Header.Padding || !p.KeepPadding:
+		return nil
+	case int(p.PaddingSize) > len(p.Payload):
+		return fmt.Errorf("%w: PaddingSize %d exceeds the payload size %d",
+			errInvalidRTPPadding, p.PaddingSize, len(p.Payload))
+	case p.Payload[len(p.Payload)-1] != p.PaddingSize:
+		return fmt.Errorf("%w: padding count byte %d does not match PaddingSize %d",
+			errInvalidRTPPadding, p.Payload[len(p.Payload)-1], p.PaddingSize)
+	default:
+		return nil
+	}
+}
+
+// PayloadWithoutPadding returns Payload without the padding that KeepPadding
+// left at its end. Without KeepPadding it returns Payload, which then never
+// holds padding.
+func (p *Packet) PayloadWithoutPadding() []byte {
+	if !p.KeepPadding || !p.Header.Padding || int(p.PaddingSize) > len(p.Payload) {
+		return p.Payload
+	}
+
+	return p.Payload[:len(p.Payload)-int(p.PaddingSize)]
+}
+
+// TotalMarshalSize returns the total size of packets once marshaled.
+func TotalMarshalSize(packets []*Packet) int {
+	size := 0
+	for _, p := range packets {
+		size += p.MarshalSize()
+	}
+
+	return size
 }
 
 // Clone returns a deep copy of p.
@@ -527,13 +1207,28 @@ func (p Packet) Clone() *Packet {
 		copy(clone.Payload, p.Payload)
 	}
 	clone.PaddingSize = p.PaddingSize
+	clone.KeepPadding = p.KeepPadding
 
 	return clone
 }
 
+// CSRCCopy returns a copy of the CSRC list that isn't affected by later
+// Unmarshal calls, or nil if there are no CSRCs.
+func (h *Header) CSRCCopy() []uint32 {
+	if len(h.CSRC) == 0 {
+		return nil
+	}
+
+	csrc := make([]uint32, len(h.CSRC))
+	copy(csrc, h.CSRC)
+
+	return csrc
+}
+
 // Clone returns a deep copy h.
 func (h Header) Clone() Header {
 	clone := h
+	clone.rawExtension = nil
 	if h.CSRC != nil {
 		clone.CSRC = make([]uint32, len(h.CSRC))
 		copy(clone.CSRC, h.CSRC)
@@ -552,3 +1247,24 @@ func (h Header) Clone() Header {
 
 	return clone
 }
+
+// CloneWithExtensions returns a deep copy of h keeping only the extensions
+// with the given ids. The extension bit is cleared if none of them remain.
+func (h Header) CloneWithExtensions(ids ...uint8) Header {
+	filtered := h
+	filtered.Extensions = nil
+	if h.Extension {
+		for _, extension := range h.Extensions {
+			for _, id := range ids {
+				if extension.id == id {
+					filtered.Extensions = append(filtered.Extensions, extension)
+
+					break
+				}
+			}
+		}
+	}
+	filtered.Extension = len(filtered.Extensions) > 0
+
+	return filtered.Clone()
+}
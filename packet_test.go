@@ -8,7 +8,9 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -34,6 +36,7 @@ func TestBasic(t *testing.T) { // nolint:maintidx,cyclop
 					0xFF, 0xFF, 0xFF, 0xFF,
 				}},
 			},
+			rawExtension:   rawPkt[16:20],
 			Version:        2,
 			PayloadType:    96,
 			SequenceNumber: 27023,
@@ -85,6 +88,7 @@ func TestBasic(t *testing.T) { // nolint:maintidx,cyclop
 					0xFF, 0xFF, 0xFF, 0xFF,
 				}},
 			},
+			rawExtension:   rawPkt[16:20],
 			Version:        2,
 			PayloadType:    96,
 			SequenceNumber: 27023,
@@ -117,6 +121,7 @@ func TestBasic(t *testing.T) { // nolint:maintidx,cyclop
 					0xFF, 0xFF, 0xFF, 0xFF,
 				}},
 			},
+			rawExtension:   rawPkt[16:20],
 			Version:        2,
 			PayloadType:    96,
 			SequenceNumber: 27023,
@@ -149,6 +154,7 @@ func TestBasic(t *testing.T) { // nolint:maintidx,cyclop
 					0xFF, 0xFF, 0xFF, 0xFF,
 				}},
 			},
+			rawExtension:   rawPkt[16:20],
 			Version:        2,
 			PayloadType:    96,
 			SequenceNumber: 27023,
@@ -778,6 +784,41 @@ func TestRFC8285DelExtension(t *testing.T) {
 	}
 }
 
+func TestRFC8285DelExtensionFunc(t *testing.T) {
+	header := &Header{}
+	if removed := header.DelExtensionFunc(func(uint8, []byte) bool { return true }); removed != 0 {
+		t.Fatalf("Expected no extension removed without extensions, got %d", removed)
+	}
+
+	header = &Header{
+		Extension:        true,
+		ExtensionProfile: extensionProfileTwoByte,
+		Extensions: []Extension{
+			{id: 1, payload: []byte{}},
+			{id: 2, payload: []byte{0xAA}},
+			{id: 3, payload: nil},
+			{id: 4, payload: []byte{0xBB, 0xCC}},
+		},
+	}
+
+	removed := header.DelExtensionFunc(func(_ uint8, payload []byte) bool { return len(payload) == 0 })
+	if removed != 2 {
+		t.Fatalf("Expected 2 extensions removed, got %d", removed)
+	}
+
+	expected := []Extension{{id: 2, payload: []byte{0xAA}}, {id: 4, payload: []byte{0xBB, 0xCC}}}
+	if !reflect.DeepEqual(header.Extensions, expected) {
+		t.Fatalf("Expected %v, got %v", expected, header.Extensions)
+	}
+
+	if removed = header.DelExtensionFunc(func(id uint8, _ []byte) bool { return id == 4 }); removed != 1 {
+		t.Fatalf("Expected 1 extension removed, got %d", removed)
+	}
+	if ext := header.GetExtension(2); !bytes.Equal(ext, []byte{0xAA}) {
+		t.Fatalf("Extension 2 should be intact, got %v", ext)
+	}
+}
+
 func TestRFC8285GetExtensionIDs(t *testing.T) {
 	payload := []byte{
 		// Payload
@@ -826,6 +867,31 @@ func TestRFC8285GetExtensionIDs(t *testing.T) {
 	}
 }
 
+func TestNumExtensions(t *testing.T) {
+	header := &Header{}
+	if n := header.NumExtensions(); n != 0 {
+		t.Errorf("Expected no extensions, got %d", n)
+	}
+
+	for id := uint8(1); id <= 3; id++ {
+		if err := header.SetExtension(id, []byte{0xAA}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := header.NumExtensions(); n != len(header.Extensions) || n != 3 {
+		t.Errorf("Expected 3 extensions, got %d", n)
+	}
+
+	header.Extension = false
+	if n := header.NumExtensions(); n != 0 {
+		t.Errorf("Expected no extensions when disabled, got %d", n)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { header.NumExtensions() }); allocs != 0 {
+		t.Errorf("NumExtensions allocated %f times", allocs)
+	}
+}
+
 func TestRFC8285GetExtensionIDsReturnsErrorWhenExtensionsDisabled(t *testing.T) {
 	payload := []byte{
 		// Payload
@@ -1050,6 +1116,35 @@ func TestRFC8285OneByteExtensionTermianteProcessingWhenReservedIDEncountered(t *
 	}
 }
 
+func TestRFC8285OneByteExtensionContinuePastReservedID(t *testing.T) {
+	reservedIDPkt := []byte{
+		0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64,
+		0x27, 0x82, 0xBE, 0xDE, 0x00, 0x01, 0xF0, 0x10, 0xBB, 0x00, 0x98, 0x36,
+	}
+
+	packet := &Packet{}
+	if err := packet.Unmarshal(reservedIDPkt); err != nil {
+		t.Fatal(err)
+	}
+	if len(packet.Extensions) != 0 {
+		t.Errorf("Extensions should be empty by default, got %v", packet.Extensions)
+	}
+	if !bytes.Equal(packet.Payload, reservedIDPkt[17:]) {
+		t.Errorf("Expected payload %v, got %v", reservedIDPkt[17:], packet.Payload)
+	}
+
+	packet = &Packet{Header: Header{ContinuePastReservedExtID: true}}
+	if err := packet.Unmarshal(reservedIDPkt); err != nil {
+		t.Fatal(err)
+	}
+	if ext := packet.GetExtension(1); !bytes.Equal(ext, []byte{0xBB}) {
+		t.Errorf("Expected extension 1 after the reserved ID, got %v", ext)
+	}
+	if !bytes.Equal(packet.Payload, reservedIDPkt[20:]) {
+		t.Errorf("Expected payload %v, got %v", reservedIDPkt[20:], packet.Payload)
+	}
+}
+
 func TestRFC8285OneByteSetExtensionShouldErrorWhenPayloadTooLarge(t *testing.T) {
 	payload := []byte{
 		// Payload
@@ -1083,6 +1178,73 @@ func TestRFC8285OneByteSetExtensionShouldErrorWhenPayloadTooLarge(t *testing.T)
 	}
 }
 
+func TestRFC8285OneByteSetExtensionShouldErrorWhenPayloadEmpty(t *testing.T) {
+	packet := &Packet{
+		Header: Header{
+			Extension:        true,
+			ExtensionProfile: 0xBEDE,
+			Version:          2,
+		},
+	}
+
+	if err := packet.SetExtension(1, []byte{}); !errors.Is(err, errRFC8285OneByteHeaderSize) {
+		t.Errorf("SetExtension did not error on empty payload: %v", err)
+	}
+
+	ext := &OneByteHeaderExtension{}
+	if _, err := ext.Unmarshal([]byte{0xBE, 0xDE, 0x00, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ext.Set(1, nil); !errors.Is(err, errRFC8285OneByteHeaderSize) {
+		t.Errorf("OneByteHeaderExtension.Set did not error on empty payload: %v", err)
+	}
+}
+
+func TestRFC8285TwoByteSetExtensionWithEmptyPayload(t *testing.T) {
+	// Adding an empty extension to a header without extensions picks the two byte profile
+	packet := &Packet{
+		Header: Header{
+			Version: 2,
+		},
+		Payload: []byte{0x98, 0x36, 0xbe, 0x88, 0x9e},
+	}
+
+	if err := packet.SetExtension(1, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if packet.ExtensionProfile != extensionProfileTwoByte {
+		t.Fatalf("Extension profile should be 0x1000, got %#x", packet.ExtensionProfile)
+	}
+	if err := packet.SetExtension(2, []byte{0xBB}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0x90, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x10, 0x00, 0x00, 0x02, 0x01, 0x00, 0x02, 0x01,
+		0xBB, 0x00, 0x00, 0x00, 0x98, 0x36, 0xbe, 0x88, 0x9e,
+	}
+	if !bytes.Equal(raw, expected) {
+		t.Fatalf("Marshal failed raw \nMarshaled:\n%s\nexpected:\n%s", hex.Dump(raw), hex.Dump(expected))
+	}
+
+	unmarshaled := &Packet{}
+	if err := unmarshaled.Unmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if ext := unmarshaled.GetExtension(1); ext == nil || len(ext) != 0 {
+		t.Errorf("Empty extension should roundtrip, got %v", ext)
+	}
+	if ext := unmarshaled.GetExtension(2); !bytes.Equal(ext, []byte{0xBB}) {
+		t.Errorf("Extension has incorrect data. Got: %v, Expected: %v", ext, []byte{0xBB})
+	}
+}
+
 func TestRFC8285TwoByteSetExtensionShouldEnableExensionsWhenAdding(t *testing.T) {
 	payload := []byte{
 		// Payload
@@ -1256,6 +1418,289 @@ func TestRFC8285Padding(t *testing.T) {
 	}
 }
 
+func TestRFC8285TrailingPadding(t *testing.T) {
+	for name, test := range map[string]struct {
+		raw        []byte
+		extensions []Extension
+	}{
+		"OneByte": {
+			raw: []byte{
+				0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64, 0x27, 0x82,
+				0xBE, 0xDE, 0x00, 0x03, // one byte profile, length
+				0x10, 0xAA, 0x21, 0xBB, 0xCC, 0x00, 0x00, 0x00, // two elements, padding
+				0x00, 0x00, 0x00, 0x00, // padding word
+				0x98, 0x36, // payload
+			},
+			extensions: []Extension{{id: 1, payload: []byte{0xAA}}, {id: 2, payload: []byte{0xBB, 0xCC}}},
+		},
+		"TwoByte": {
+			raw: []byte{
+				0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64, 0x27, 0x82,
+				0x10, 0x00, 0x00, 0x03, // two byte profile, length
+				0x01, 0x01, 0xAA, 0x00, 0x00, 0x00, 0x00, 0x00, // element, padding
+				0x00, 0x00, 0x00, 0x00, // padding word
+				0x98, 0x36, // payload
+			},
+			extensions: []Extension{{id: 1, payload: []byte{0xAA}}},
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			packet := &Packet{}
+			if err := packet.Unmarshal(test.raw); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(packet.Extensions, test.extensions) {
+				t.Errorf("Expected extensions %v, got %v", test.extensions, packet.Extensions)
+			}
+			if !bytes.Equal(packet.Payload, []byte{0x98, 0x36}) {
+				t.Errorf("Unexpected payload %v", packet.Payload)
+			}
+
+			// The padding is consumed up to the declared extension end
+			header := &Header{}
+			n, err := header.Unmarshal(test.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(test.raw)-2 {
+				t.Errorf("Expected to read %d bytes, read %d", len(test.raw)-2, n)
+			}
+		})
+	}
+}
+
+func TestExtensionPaddingByte(t *testing.T) {
+	header := Header{
+		Version:              2,
+		SequenceNumber:       27023,
+		Timestamp:            3653407706,
+		SSRC:                 476325762,
+		ExtensionPaddingByte: 0xFF,
+	}
+	if err := header.SetExtension(1, []byte{0xAA}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0x90, 0x00, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64, 0x27, 0x82,
+		0xBE, 0xDE, 0x00, 0x01, 0x10, 0xAA, 0xFF, 0xFF,
+	}
+	raw, err := header.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("Expected %v, got %v", expected, raw)
+	}
+	if header.MarshalSize() != len(expected) {
+		t.Errorf("Expected size %d, got %d", len(expected), header.MarshalSize())
+	}
+}
+
+func TestCryptexExtensionProfiles(t *testing.T) {
+	for name, test := range map[string]struct {
+		profile   uint16
+		invalidID uint8
+		payload   []byte
+	}{
+		"OneByte": {profile: extensionProfileCryptexOneByte, invalidID: 15, payload: []byte{0xAA}},
+		"TwoByte": {profile: extensionProfileCryptexTwoByte, invalidID: 0, payload: []byte{}},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			header := Header{
+				Version:          2,
+				SSRC:             0x1234ABCD,
+				Extension:        true,
+				ExtensionProfile: test.profile,
+			}
+			if err := header.SetExtension(1, test.payload); err != nil {
+				t.Fatal(err)
+			}
+			if err := header.SetExtension(2, []byte{0xBB, 0xCC}); err != nil {
+				t.Fatal(err)
+			}
+			if err := header.SetExtension(test.invalidID, []byte{0xDD}); err == nil {
+				t.Errorf("Expected an error for extension ID %d", test.invalidID)
+			}
+
+			raw, err := header.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) != header.MarshalSize() {
+				t.Errorf("Expected %d bytes, got %d", header.MarshalSize(), len(raw))
+			}
+
+			parsed := &Header{ParseCryptexExtensions: true}
+			if _, err = parsed.Unmarshal(raw); err != nil {
+				t.Fatal(err)
+			}
+			if parsed.ExtensionProfile != test.profile {
+				t.Errorf("Expected profile %#x, got %#x", test.profile, parsed.ExtensionProfile)
+			}
+			if ext := parsed.GetExtension(1); !bytes.Equal(ext, test.payload) {
+				t.Errorf("Expected extension 1 %v, got %v", test.payload, ext)
+			}
+			if ext := parsed.GetExtension(2); !bytes.Equal(ext, []byte{0xBB, 0xCC}) {
+				t.Errorf("Expected extension 2 %v, got %v", []byte{0xBB, 0xCC}, ext)
+			}
+		})
+	}
+
+	// Merging an extension too large for one byte headers keeps the cryptex profile
+	header := Header{Extension: true, ExtensionProfile: extensionProfileCryptexOneByte}
+	if err := header.MergeExtensions([]Extension{{id: 1, payload: make([]byte, 17)}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if header.ExtensionProfile != extensionProfileCryptexTwoByte {
+		t.Errorf("Expected profile %#x, got %#x", extensionProfileCryptexTwoByte, header.ExtensionProfile)
+	}
+}
+
+func TestCSRCCopy(t *testing.T) {
+	header := &Header{}
+	if header.CSRCCopy() != nil {
+		t.Fatal("Expected nil copy without CSRCs")
+	}
+
+	_, err := header.Unmarshal([]byte{
+		0x82, 0x60, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x00, 0x0B,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliased := header.CSRC
+	owned := header.CSRCCopy()
+
+	_, err = header.Unmarshal([]byte{
+		0x82, 0x60, 0x00, 0x02, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x0C, 0x00, 0x00, 0x00, 0x0D,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(owned, []uint32{0x0A, 0x0B}) {
+		t.Errorf("CSRCCopy changed by Unmarshal: %v", owned)
+	}
+	if !reflect.DeepEqual(aliased, []uint32{0x0C, 0x0D}) {
+		t.Errorf("Expected CSRC to be reused by Unmarshal, got %v", aliased)
+	}
+}
+
+func TestOptimizeExtensionProfile(t *testing.T) {
+	for name, test := range map[string]struct {
+		profile    uint16
+		extensions []Extension
+		expected   uint16
+	}{
+		"Eligible": {
+			profile:    extensionProfileTwoByte,
+			extensions: []Extension{{id: 1, payload: []byte{0xAA}}, {id: 14, payload: make([]byte, 16)}},
+			expected:   extensionProfileOneByte,
+		},
+		"EligibleCryptex": {
+			profile:    extensionProfileCryptexTwoByte,
+			extensions: []Extension{{id: 1, payload: []byte{0xAA}}},
+			expected:   extensionProfileCryptexOneByte,
+		},
+		"IDTooLarge": {
+			profile:    extensionProfileTwoByte,
+			extensions: []Extension{{id: 1, payload: []byte{0xAA}}, {id: 15, payload: []byte{0xBB}}},
+			expected:   extensionProfileTwoByte,
+		},
+		"PayloadTooLarge": {
+			profile:    extensionProfileTwoByte,
+			extensions: []Extension{{id: 1, payload: make([]byte, 17)}},
+			expected:   extensionProfileTwoByte,
+		},
+		"EmptyPayload": {
+			profile:    extensionProfileTwoByte,
+			extensions: []Extension{{id: 1, payload: []byte{}}},
+			expected:   extensionProfileTwoByte,
+		},
+		"RFC3550": {
+			profile:    0x1234,
+			extensions: []Extension{{id: 0, payload: []byte{0xAA, 0xBB, 0xCC, 0xDD}}},
+			expected:   0x1234,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			header := Header{Version: 2, Extension: true, ExtensionProfile: test.profile, Extensions: test.extensions}
+			before := header.MarshalSize()
+
+			header.OptimizeExtensionProfile()
+			if header.ExtensionProfile != test.expected {
+				t.Fatalf("Expected profile %#x, got %#x", test.expected, header.ExtensionProfile)
+			}
+			if !reflect.DeepEqual(header.Extensions, test.extensions) {
+				t.Fatalf("Extensions changed: %v", header.Extensions)
+			}
+			if header.MarshalSize() > before {
+				t.Fatalf("Header grew from %d to %d bytes", before, header.MarshalSize())
+			}
+
+			raw, err := header.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Decrypted cryptex extensions are parsed, other profiles are unaffected
+			parsed := &Header{ParseCryptexExtensions: true}
+			if _, err = parsed.Unmarshal(raw); err != nil {
+				t.Fatal(err)
+			}
+			for _, extension := range test.extensions {
+				if !bytes.Equal(parsed.GetExtension(extension.id), extension.payload) {
+					t.Errorf("Extension %d not preserved", extension.id)
+				}
+			}
+		})
+	}
+}
+
+func TestCryptexExtensionOpaque(t *testing.T) {
+	rawPkt := []byte{
+		0x90, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xc0, 0xde, 0x00, 0x01, // cryptex one byte profile, length
+		0x1f, 0xaa, 0xbb, 0xcc, // encrypted extension
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, // payload
+		0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+	}
+
+	// The encrypted extension is kept as is, it never eats the payload
+	packet := &Packet{}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packet.Payload, rawPkt[20:]) {
+		t.Errorf("Payload = %v, want %v", packet.Payload, rawPkt[20:])
+	}
+	expected := []Extension{{id: 0, payload: rawPkt[16:20]}}
+	if !reflect.DeepEqual(packet.Extensions, expected) {
+		t.Errorf("Extensions = %v, want %v", packet.Extensions, expected)
+	}
+
+	buf, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, rawPkt) {
+		t.Errorf("Marshal() = %v, want %v", buf, rawPkt)
+	}
+
+	// Parsed as decrypted elements, the length of the first one exceeds the extension
+	packet = &Packet{Header: Header{ParseCryptexExtensions: true}}
+	if err := packet.Unmarshal(rawPkt); !errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficientForExtension, err)
+	}
+}
+
 func TestRFC3550SetExtensionShouldErrorWhenNonZero(t *testing.T) {
 	payload := []byte{
 		// Payload
@@ -1379,87 +1824,1300 @@ func TestUnmarshal_ErrorHandling(t *testing.T) {
 	}
 }
 
-func TestRoundtrip(t *testing.T) {
+func TestUnmarshal_MissingPaddingByte(t *testing.T) {
 	rawPkt := []byte{
-		0x00, 0x10, 0x23, 0x45, 0x12, 0x34, 0x45, 0x67, 0xCC, 0xDD, 0xEE, 0xFF,
-		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
+		0xa0, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
 	}
-	payload := rawPkt[12:]
 
 	packet := &Packet{}
-	if err := packet.Unmarshal(rawPkt); err != nil {
-		t.Fatal(err)
-	}
-	if !bytes.Equal(payload, packet.Payload) {
-		t.Errorf("p.Payload must be same as payload.\n  payload: %+v,\np.Payload: %+v",
-			payload, packet.Payload,
-		)
+	if err := packet.Unmarshal(rawPkt); !errors.Is(err, errMissingPaddingByte) {
+		t.Errorf("Expected error: %v, got: %v", errMissingPaddingByte, err)
 	}
 
-	buf, err := packet.Marshal()
-	if err != nil {
-		t.Fatal(err)
-	}
-	if !bytes.Equal(rawPkt, buf) {
-		t.Errorf("buf must be same as rawPkt.\n   buf: %+v,\nrawPkt: %+v", buf, rawPkt)
+	// A padding count larger than the packet is still reported as too small
+	rawPkt = append(rawPkt, 0x02)
+	if err := packet.Unmarshal(rawPkt); !errors.Is(err, errTooSmall) {
+		t.Errorf("Expected error: %v, got: %v", errTooSmall, err)
 	}
-	if !bytes.Equal(payload, packet.Payload) {
-		t.Errorf("p.Payload must be same as payload.\n  payload: %+v,\np.Payload: %+v",
-			payload, packet.Payload,
-		)
+
+	// Without the padding bit a header-only packet is valid
+	rawPkt = []byte{0x80, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64, 0x27, 0x82}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Errorf("Unexpected error: %v", err)
 	}
 }
 
-func TestCloneHeader(t *testing.T) {
-	header := Header{
-		Marker:           true,
-		Extension:        true,
-		ExtensionProfile: 1,
-		Extensions: []Extension{
-			{0, []byte{
-				0xFF, 0xFF, 0xFF, 0xFF,
-			}},
-		},
-		Version:        2,
-		PayloadType:    96,
-		SequenceNumber: 27023,
-		Timestamp:      3653407706,
-		SSRC:           476325762,
-		CSRC:           []uint32{},
-	}
-	clone := header.Clone()
-	if !reflect.DeepEqual(header, clone) {
-		t.Errorf("Cloned clone does not match the original")
+func TestPayloadWithoutPadding(t *testing.T) {
+	rawPkt := []byte{
+		0xa0, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0x98, 0x36, // payload
+		0x00, 0x00, 0x03, // padding
 	}
 
-	header.CSRC = append(header.CSRC, 1)
-	if len(clone.CSRC) == len(header.CSRC) {
-		t.Errorf("Expected CSRC to be unchanged")
+	for name, keepPadding := range map[string]bool{"Trimmed": false, "KeepPadding": true} {
+		keepPadding := keepPadding
+		t.Run(name, func(t *testing.T) {
+			packet := &Packet{KeepPadding: keepPadding}
+			if err := packet.Unmarshal(rawPkt); err != nil {
+				t.Fatal(err)
+			}
+			if packet.PaddingSize != 3 {
+				t.Errorf("PaddingSize = %d, want 3", packet.PaddingSize)
+			}
+
+			wantPayload := rawPkt[12:14]
+			if keepPadding {
+				wantPayload = rawPkt[12:]
+			}
+			if !bytes.Equal(packet.Payload, wantPayload) {
+				t.Errorf("Payload = %v, want %v", packet.Payload, wantPayload)
+			}
+			if got := packet.PayloadWithoutPadding(); !bytes.Equal(got, rawPkt[12:14]) {
+				t.Errorf("PayloadWithoutPadding() = %v, want %v", got, rawPkt[12:14])
+			}
+
+			if size := packet.MarshalSize(); size != len(rawPkt) {
+				t.Errorf("MarshalSize() = %d, want %d", size, len(rawPkt))
+			}
+			buf, err := packet.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf, rawPkt) {
+				t.Errorf("Marshal() = %v, want %v", buf, rawPkt)
+			}
+		})
+	}
+
+	// Without the padding bit Payload is returned as is
+	packet := &Packet{KeepPadding: true, Payload: []byte{0x01, 0x02}, PaddingSize: 1}
+	if got := packet.PayloadWithoutPadding(); !bytes.Equal(got, packet.Payload) {
+		t.Errorf("PayloadWithoutPadding() = %v, want %v", got, packet.Payload)
+	}
+}
+
+func TestValidatePadding(t *testing.T) {
+	for name, test := range map[string]struct {
+		packet Packet
+		err    error
+	}{
+		"NoPadding": {
+			packet: Packet{Payload: []byte{0x01}},
+		},
+		"Padding": {
+			packet: Packet{Header: Header{Padding: true}, Payload: []byte{0x01}, PaddingSize: 4},
+		},
+		"KeptPadding": {
+			packet: Packet{Header: Header{Padding: true}, Payload: []byte{0x01, 0x00, 0x02}, PaddingSize: 2, KeepPadding: true},
+		},
+		"PaddingBitWithoutSize": {
+			packet: Packet{Header: Header{Padding: true}, Payload: []byte{0x01}},
+			err:    errInvalidRTPPadding,
+		},
+		"SizeWithoutPaddingBit": {
+			packet: Packet{Payload: []byte{0x01}, PaddingSize: 4},
+			err:    errInvalidRTPPadding,
+		},
+		"KeptPaddingExceedsPayload": {
+			packet: Packet{Header: Header{Padding: true}, Payload: []byte{0x02}, PaddingSize: 2, KeepPadding: true},
+			err:    errInvalidRTPPadding,
+		},
+		"KeptPaddingCountMismatch": {
+			packet: Packet{Header: Header{Padding: true}, Payload: []byte{0x01, 0x00, 0x03}, PaddingSize: 2, KeepPadding: true},
+			err:    errInvalidRTPPadding,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if err := test.packet.ValidatePadding(); !errors.Is(err, test.err) {
+				t.Fatalf("Expected error: %v, got: %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_ExtensionLengthInBytes(t *testing.T) {
+	rawPkt := []byte{
+		0x90, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xbe, 0xde, 0x00, 0x03, // one byte profile, length in bytes
+		0x11, 0xaa, 0xbb, // extension without padding
+		0x98, 0x36, 0xbe, 0x88, 0x9e, // payload
+	}
+
+	packet := &Packet{}
+	if err := packet.Unmarshal(rawPkt); !errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		t.Fatalf("Expected %v, got %v", errHeaderSizeInsufficientForExtension, err)
+	}
+
+	packet = &Packet{Header: Header{ExtensionLengthInBytes: true}}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if ext := packet.GetExtension(1); !bytes.Equal(ext, []byte{0xaa, 0xbb}) {
+		t.Errorf("Extension = %v, want %v", ext, []byte{0xaa, 0xbb})
+	}
+	if !bytes.Equal(packet.Payload, rawPkt[19:]) {
+		t.Errorf("Payload = %v, want %v", packet.Payload, rawPkt[19:])
+	}
+
+	// Marshal writes the standard length in words
+	buf, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append(append([]byte{}, rawPkt[:15]...), 0x01, 0x11, 0xaa, 0xbb, 0x00), rawPkt[19:]...)
+	if !bytes.Equal(buf, expected) {
+		t.Errorf("Marshal() = %v, want %v", buf, expected)
+	}
+}
+
+func TestUnmarshal_DuplicateExtensionIDs(t *testing.T) {
+	for name, raw := range map[string][]byte{
+		"OneByte": {
+			0x90, 0xe0, 0x69, 0x8f,
+			0xd9, 0xc2, 0x93, 0xda, // timestamp
+			0x1c, 0x64, 0x27, 0x82, // SSRC
+			0xbe, 0xde, 0x00, 0x01, // one byte profile, length
+			0x10, 0xaa, 0x10, 0xbb,
+			0x98, 0x36, // payload
+		},
+		"TwoByte": {
+			0x90, 0xe0, 0x69, 0x8f,
+			0xd9, 0xc2, 0x93, 0xda, // timestamp
+			0x1c, 0x64, 0x27, 0x82, // SSRC
+			0x10, 0x00, 0x00, 0x02, // two byte profile, length
+			0x05, 0x01, 0xaa, 0x05,
+			0x01, 0xbb, 0x00, 0x00,
+			0x98, 0x36, // payload
+		},
+	} {
+		raw := raw
+		t.Run(name, func(t *testing.T) {
+			packet := &Packet{}
+			if err := packet.Unmarshal(raw); err != nil {
+				t.Fatal(err)
+			}
+			if len(packet.Extensions) != 2 {
+				t.Fatalf("Expected duplicates to be kept by default, got %d extensions", len(packet.Extensions))
+			}
+			if id := packet.Extensions[0].id; !bytes.Equal(packet.GetExtension(id), packet.Extensions[0].payload) {
+				t.Fatal("Expected GetExtension to return the first duplicate")
+			}
+
+			packet = &Packet{Header: Header{RejectDuplicateExtensionIDs: true}}
+			if err := packet.Unmarshal(raw); !errors.Is(err, errDuplicateExtensionID) {
+				t.Fatalf("Expected %v, got %v", errDuplicateExtensionID, err)
+			}
+		})
+	}
+
+	// Distinct IDs are accepted in strict mode
+	packet := &Packet{Header: Header{RejectDuplicateExtensionIDs: true}}
+	if err := packet.Unmarshal([]byte{
+		0x90, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xbe, 0xde, 0x00, 0x01, // one byte profile, length
+		0x10, 0xaa, 0x20, 0xbb,
+		0x98, 0x36, // payload
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshal_ExtensionLimits(t *testing.T) {
+	// RFC 3550 extension claiming 65535 words, far more than the buffer holds
+	hugeExtension := []byte{
+		0x90, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0x00, 0x01, 0xff, 0xff, // profile, length
+		0x00, 0x00, 0x00, 0x00,
+	}
+
+	header := &Header{MaxExtensionBytes: 1024}
+	if _, err := header.Unmarshal(hugeExtension); !errors.Is(err, errExtensionTooLarge) {
+		t.Errorf("Expected error: %v, got: %v", errExtensionTooLarge, err)
+	}
+
+	header = &Header{}
+	if _, err := header.Unmarshal(hugeExtension); !errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		t.Errorf("Expected error: %v, got: %v", errHeaderSizeInsufficientForExtension, err)
+	}
+
+	threeExtensions := []byte{
+		0x90, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xbe, 0xde, 0x00, 0x02, // one byte profile, length
+		0x10, 0xaa, 0x20, 0xbb,
+		0x30, 0xcc, 0x00, 0x00,
+		0x98, 0x36, // payload
+	}
+
+	packet := &Packet{Header: Header{MaxExtensionElements: 2}}
+	if err := packet.Unmarshal(threeExtensions); !errors.Is(err, errTooManyExtensions) {
+		t.Errorf("Expected error: %v, got: %v", errTooManyExtensions, err)
+	}
+
+	packet = &Packet{Header: Header{MaxExtensionBytes: 8, MaxExtensionElements: 3}}
+	if err := packet.Unmarshal(threeExtensions); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(packet.Extensions) != 3 {
+		t.Errorf("Expected 3 extensions, got %d", len(packet.Extensions))
+	}
+}
+
+func TestUnmarshal_TolerantLayout(t *testing.T) {
+	// CC=1 and X=1, with the extension placed before the CSRC list
+	malformed := []byte{
+		0x91, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xBE, 0xDE, 0x00, 0x01, // one byte profile, length
+		0x10, 0xAA, 0x20, 0xBB,
+		0x11, 0x22, 0x33, 0x44, // CSRC
+		0x98, 0x36, // payload
+	}
+
+	packet := &Packet{}
+	if err := packet.Unmarshal(malformed); !errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		t.Fatalf("Expected %v by default, got %v", errHeaderSizeInsufficientForExtension, err)
+	}
+
+	packet = &Packet{Header: Header{TolerantLayout: true}}
+	if err := packet.Unmarshal(malformed); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(packet.CSRC, []uint32{0x11223344}) {
+		t.Errorf("Unexpected CSRC %v", packet.CSRC)
+	}
+	if !bytes.Equal(packet.GetExtension(1), []byte{0xAA}) || !bytes.Equal(packet.GetExtension(2), []byte{0xBB}) {
+		t.Errorf("Unexpected extensions %v", packet.Extensions)
+	}
+	if !bytes.Equal(packet.Payload, []byte{0x98, 0x36}) {
+		t.Errorf("Unexpected payload %v", packet.Payload)
+	}
+
+	// A packet that is broken in both layouts still fails
+	if err := packet.Unmarshal(malformed[:22]); !errors.Is(err, errHeaderSizeInsufficientForExtension) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficientForExtension, err)
+	}
+
+	// The misplaced extension length is read as ExtensionLengthInBytes says
+	malformedInBytes := []byte{
+		0x91, 0xe0, 0x69, 0x8f,
+		0xd9, 0xc2, 0x93, 0xda, // timestamp
+		0x1c, 0x64, 0x27, 0x82, // SSRC
+		0xBE, 0xDE, 0x00, 0x03, // one byte profile, length in bytes
+		0x10, 0xAA, 0x00,
+		0x11, 0x22, 0x33, 0x44, // CSRC
+		0x98, 0x36, // payload
+	}
+
+	packet = &Packet{Header: Header{TolerantLayout: true, ExtensionLengthInBytes: true}}
+	if err := packet.Unmarshal(malformedInBytes); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(packet.CSRC, []uint32{0x11223344}) {
+		t.Errorf("Unexpected CSRC %v", packet.CSRC)
+	}
+	if !bytes.Equal(packet.GetExtension(1), []byte{0xAA}) {
+		t.Errorf("Unexpected extensions %v", packet.Extensions)
+	}
+	if !bytes.Equal(packet.Payload, []byte{0x98, 0x36}) {
+		t.Errorf("Unexpected payload %v", packet.Payload)
+	}
+}
+
+func TestRoundtrip(t *testing.T) {
+	rawPkt := []byte{
+		0x00, 0x10, 0x23, 0x45, 0x12, 0x34, 0x45, 0x67, 0xCC, 0xDD, 0xEE, 0xFF,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
+	}
+	payload := rawPkt[12:]
+
+	packet := &Packet{}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, packet.Payload) {
+		t.Errorf("p.Payload must be same as payload.\n  payload: %+v,\np.Payload: %+v",
+			payload, packet.Payload,
+		)
+	}
+
+	buf, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rawPkt, buf) {
+		t.Errorf("buf must be same as rawPkt.\n   buf: %+v,\nrawPkt: %+v", buf, rawPkt)
+	}
+	if !bytes.Equal(payload, packet.Payload) {
+		t.Errorf("p.Payload must be same as payload.\n  payload: %+v,\np.Payload: %+v",
+			payload, packet.Payload,
+		)
+	}
+}
+
+func TestCloneHeader(t *testing.T) {
+	header := Header{
+		Marker:           true,
+		Extension:        true,
+		ExtensionProfile: 1,
+		Extensions: []Extension{
+			{0, []byte{
+				0xFF, 0xFF, 0xFF, 0xFF,
+			}},
+		},
+		Version:        2,
+		PayloadType:    96,
+		SequenceNumber: 27023,
+		Timestamp:      3653407706,
+		SSRC:           476325762,
+		CSRC:           []uint32{},
+	}
+	clone := header.Clone()
+	if !reflect.DeepEqual(header, clone) {
+		t.Errorf("Cloned clone does not match the original")
+	}
+
+	header.CSRC = append(header.CSRC, 1)
+	if len(clone.CSRC) == len(header.CSRC) {
+		t.Errorf("Expected CSRC to be unchanged")
+	}
+	header.Extensions[0].payload[0] = 0x1F
+	if clone.Extensions[0].payload[0] == 0x1F {
+		t.Errorf("Expected Extensions to be unchanged")
+	}
+}
+
+func TestCloneHeaderWithExtensions(t *testing.T) {
+	header := Header{
+		Version:          2,
+		SequenceNumber:   27023,
+		SSRC:             476325762,
+		CSRC:             []uint32{1},
+		Extension:        true,
+		ExtensionProfile: extensionProfileOneByte,
+		Extensions: []Extension{
+			{id: 1, payload: []byte{0xAA}},
+			{id: 2, payload: []byte{0xBB}},
+			{id: 3, payload: []byte{0xCC}},
+		},
+	}
+
+	clone := header.CloneWithExtensions(3, 1, 5)
+	expected := []Extension{{id: 1, payload: []byte{0xAA}}, {id: 3, payload: []byte{0xCC}}}
+	if !clone.Extension || !reflect.DeepEqual(clone.Extensions, expected) {
+		t.Fatalf("Expected extensions %v, got %v", expected, clone.Extensions)
+	}
+	if clone.ExtensionProfile != header.ExtensionProfile || clone.SequenceNumber != header.SequenceNumber ||
+		!reflect.DeepEqual(clone.CSRC, header.CSRC) {
+		t.Fatal("Expected other fields to be cloned")
+	}
+	if len(header.Extensions) != 3 {
+		t.Fatal("Expected the original extensions to be unchanged")
+	}
+
+	header.Extensions[0].payload[0] = 0x1F
+	header.CSRC[0] = 2
+	if clone.Extensions[0].payload[0] == 0x1F || clone.CSRC[0] == 2 {
+		t.Fatal("Expected a deep copy")
+	}
+
+	clone = header.CloneWithExtensions(4)
+	if clone.Extension || len(clone.Extensions) != 0 {
+		t.Fatalf("Expected extensions to be disabled, got %v", clone.Extensions)
+	}
+	if _, err := clone.Marshal(); err != nil {
+		t.Fatal(err)
+	}
+
+	header.Extension = false
+	if clone = header.CloneWithExtensions(1); clone.Extension || len(clone.Extensions) != 0 {
+		t.Fatal("Expected no extensions when they are disabled")
+	}
+}
+
+func TestClonePacket(t *testing.T) {
+	rawPkt := []byte{
+		0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64,
+		0x27, 0x82, 0xBE, 0xDE, 0x00, 0x01, 0x50, 0xAA, 0x00, 0x00,
+		0x98, 0x36, 0xbe, 0x88, 0x9e,
+	}
+	packet := &Packet{
+		Payload: rawPkt[20:],
+	}
+
+	clone := packet.Clone()
+	if !reflect.DeepEqual(packet, clone) {
+		t.Errorf("Cloned Packet does not match the original")
+	}
+
+	packet.Payload[0] = 0x1F
+	if clone.Payload[0] == 0x1F {
+		t.Errorf("Expected Payload to be unchanged")
+	}
+}
+
+func TestAppendMarshal(t *testing.T) {
+	packet := &Packet{
+		Header: Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 27023,
+			Timestamp:      3653407706,
+			SSRC:           476325762,
+			CSRC:           []uint32{1, 2},
+		},
+		Payload: []byte{0x98, 0x36, 0xbe, 0x88, 0x9e},
+	}
+	if err := packet.SetExtension(1, []byte{0xAA, 0xBB}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedHeader, err := packet.Header.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPacket, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := []byte{0x01, 0x02, 0x03}
+	for name, dst := range map[string][]byte{
+		"Nil":             nil,
+		"Prefix":          append([]byte{}, prefix...),
+		"PrefixWithSpare": append(make([]byte, 0, 256), prefix...),
+	} {
+		header, err := packet.Header.AppendMarshal(append([]byte{}, dst...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(header, append(append([]byte{}, dst...), expectedHeader...)) {
+			t.Errorf("%s: unexpected header %x", name, header)
+		}
+
+		buf, err := packet.AppendMarshal(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf[:len(dst)], dst) || !bytes.Equal(buf[len(dst):], expectedPacket) {
+			t.Errorf("%s: unexpected packet %x", name, buf)
+		}
+	}
+
+	// The spare capacity of dst is used without reallocating
+	dst := append(make([]byte, 0, 256), prefix...)
+	buf, err := packet.AppendMarshal(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &buf[0] != &dst[0] {
+		t.Error("Expected the capacity of dst to be reused")
+	}
+
+	packet.Header.Padding = true
+	buf, err = packet.AppendMarshal(prefix)
+	if !errors.Is(err, errInvalidRTPPadding) {
+		t.Errorf("Expected %v, got %v", errInvalidRTPPadding, err)
+	}
+	if !bytes.Equal(buf, prefix) {
+		t.Errorf("Expected dst to be returned unchanged on error, got %x", buf)
+	}
+}
+
+func TestTotalMarshalSize(t *testing.T) {
+	packets := []*Packet{
+		{Header: Header{Version: 2}, Payload: make([]byte, 10)},
+		{Header: Header{Version: 2, CSRC: []uint32{1}}, Payload: make([]byte, 20)},
+		{Header: Header{Version: 2, Padding: true}, Payload: make([]byte, 5), PaddingSize: 3},
+	}
+	if err := packets[0].SetExtension(1, []byte{0xAA}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf []byte
+	for _, p := range packets {
+		var err error
+		if buf, err = p.AppendMarshal(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if size := TotalMarshalSize(packets); size != len(buf) || size != 20+10+16+20+12+8 {
+		t.Errorf("Expected total size %d, got %d", len(buf), size)
+	}
+	if size := TotalMarshalSize(nil); size != 0 {
+		t.Errorf("Expected 0 for no packets, got %d", size)
+	}
+}
+
+func TestMarshalWithMTU(t *testing.T) {
+	packet := &Packet{
+		Header: Header{
+			Version:        2,
+			SequenceNumber: 27023,
+			Timestamp:      3653407706,
+			SSRC:           476325762,
+		},
+		Payload: make([]byte, 88),
+	}
+
+	buf, err := packet.MarshalWithMTU(100)
+	if err != nil {
+		t.Fatalf("Unexpected error at the MTU boundary: %v", err)
+	}
+	if len(buf) != 100 {
+		t.Errorf("Expected 100 bytes, got %d", len(buf))
+	}
+
+	if buf, err = packet.MarshalWithMTU(99); !errors.Is(err, errExceedsMTU) {
+		t.Errorf("Expected %v, got %v", errExceedsMTU, err)
+	}
+	if buf != nil {
+		t.Error("Expected no buffer when exceeding the MTU")
+	}
+
+	// Padding counts towards the MTU.
+	packet.Header.Padding = true
+	packet.PaddingSize = 1
+	if _, err = packet.MarshalWithMTU(100); !errors.Is(err, errExceedsMTU) {
+		t.Errorf("Expected %v, got %v", errExceedsMTU, err)
+	}
+	if _, err = packet.MarshalWithMTU(101); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHeaderMIDAndRID(t *testing.T) {
+	header := &Header{}
+	if _, ok := header.MID(1); ok {
+		t.Error("MID should be absent without extensions")
+	}
+
+	if err := header.SetExtension(1, []byte("audio")); err != nil {
+		t.Fatal(err)
+	}
+	if err := header.SetExtension(2, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+
+	if mid, ok := header.MID(1); !ok || mid != "audio" {
+		t.Errorf("Expected MID audio, got %q (%v)", mid, ok)
+	}
+	if rid, ok := header.RID(2); !ok || rid != "hi" {
+		t.Errorf("Expected RID hi, got %q (%v)", rid, ok)
+	}
+	if _, ok := header.RID(3); ok {
+		t.Error("RID should be absent for an unknown extension ID")
+	}
+
+	header.Extension = false
+	if _, ok := header.MID(1); ok {
+		t.Error("MID should be absent when extensions are disabled")
+	}
+}
+
+func TestHeaderMergeExtensions(t *testing.T) {
+	newHeader := func() *Header {
+		return &Header{
+			Extension:        true,
+			ExtensionProfile: extensionProfileOneByte,
+			Extensions: []Extension{
+				{id: 1, payload: []byte{0xAA}},
+				{id: 2, payload: []byte{0xBB}},
+			},
+		}
+	}
+
+	t.Run("Overwrite", func(t *testing.T) {
+		header := newHeader()
+		err := header.MergeExtensions([]Extension{
+			{id: 2, payload: []byte{0xCC}},
+			{id: 3, payload: []byte{0xDD}},
+		}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []Extension{
+			{id: 1, payload: []byte{0xAA}},
+			{id: 2, payload: []byte{0xCC}},
+			{id: 3, payload: []byte{0xDD}},
+		}
+		if !reflect.DeepEqual(header.Extensions, expected) {
+			t.Errorf("Expected %v, got %v", expected, header.Extensions)
+		}
+		if header.ExtensionProfile != extensionProfileOneByte {
+			t.Errorf("Expected one byte profile, got %#04x", header.ExtensionProfile)
+		}
+	})
+
+	t.Run("SkipExisting", func(t *testing.T) {
+		header := newHeader()
+		err := header.MergeExtensions([]Extension{
+			{id: 2, payload: []byte{0xCC}},
+			{id: 3, payload: []byte{0xDD}},
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []Extension{
+			{id: 1, payload: []byte{0xAA}},
+			{id: 2, payload: []byte{0xBB}},
+			{id: 3, payload: []byte{0xDD}},
+		}
+		if !reflect.DeepEqual(header.Extensions, expected) {
+			t.Errorf("Expected %v, got %v", expected, header.Extensions)
+		}
+	})
+
+	t.Run("PromoteToTwoByte", func(t *testing.T) {
+		header := newHeader()
+		if err := header.MergeExtensions([]Extension{{id: 20, payload: []byte{0xEE}}}, false); err != nil {
+			t.Fatal(err)
+		}
+		if header.ExtensionProfile != extensionProfileTwoByte {
+			t.Errorf("Expected two byte profile, got %#04x", header.ExtensionProfile)
+		}
+		if payload := header.GetExtension(20); !bytes.Equal(payload, []byte{0xEE}) {
+			t.Errorf("Expected merged extension, got %v", payload)
+		}
+
+		header = newHeader()
+		if err := header.MergeExtensions([]Extension{{id: 3, payload: make([]byte, 17)}}, false); err != nil {
+			t.Fatal(err)
+		}
+		if header.ExtensionProfile != extensionProfileTwoByte {
+			t.Errorf("Expected two byte profile, got %#04x", header.ExtensionProfile)
+		}
+
+		raw, err := header.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed := &Header{}
+		if _, err = parsed.Unmarshal(raw); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed.Extensions, header.Extensions) {
+			t.Errorf("Expected %v, got %v", header.Extensions, parsed.Extensions)
+		}
+	})
+
+	t.Run("NoExtensions", func(t *testing.T) {
+		header := &Header{}
+		if err := header.MergeExtensions([]Extension{{id: 1, payload: []byte{0xAA}}}, false); err != nil {
+			t.Fatal(err)
+		}
+		if !header.Extension || header.ExtensionProfile != extensionProfileOneByte {
+			t.Errorf("Expected one byte extensions to be enabled, got %v %#04x", header.Extension, header.ExtensionProfile)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		header := newHeader()
+		err := header.MergeExtensions([]Extension{
+			{id: 3, payload: []byte{0xDD}},
+			{id: 4, payload: make([]byte, 256)},
+		}, true)
+		if !errors.Is(err, errRFC8285TwoByteHeaderSize) {
+			t.Errorf("Expected %v, got %v", errRFC8285TwoByteHeaderSize, err)
+		}
+		if !reflect.DeepEqual(header, newHeader()) {
+			t.Errorf("Header should be unchanged on error, got %v", header)
+		}
+
+		header = &Header{Extension: true, ExtensionProfile: 0x1111}
+		err = header.MergeExtensions([]Extension{{id: 1, payload: []byte{0xAA}}}, true)
+		if !errors.Is(err, errRFC3550HeaderIDRange) {
+			t.Errorf("Expected %v, got %v", errRFC3550HeaderIDRange, err)
+		}
+	})
+}
+
+func TestUnmarshalPopulateRaw(t *testing.T) {
+	rawPkt := []byte{
+		0x80, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64,
+		0x27, 0x82, 0x98, 0x36, 0xbe, 0x88, 0x9e,
+	}
+
+	packet := &Packet{}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if packet.Raw != nil {
+		t.Errorf("Raw should not be populated by default, got %v", packet.Raw)
+	}
+
+	packet = &Packet{PopulateRaw: true}
+	if err := packet.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packet.Raw, rawPkt) {
+		t.Errorf("Expected Raw to be the input buffer, got %v", packet.Raw)
+	}
+}
+
+func TestHeaderMarshalToPayloadOffset(t *testing.T) {
+	header := Header{
+		Version:          2,
+		Extension:        true,
+		PayloadType:      96,
+		SequenceNumber:   27023,
+		Timestamp:        3653407706,
+		SSRC:             476325762,
+		CSRC:             []uint32{1, 2},
+		ExtensionProfile: extensionProfileOneByte,
+		Extensions:       []Extension{{id: 1, payload: []byte{0xAA, 0xBB, 0xCC}}},
+	}
+	payload := []byte{0x01, 0x02, 0x03}
+
+	buf := make([]byte, header.MarshalSize()+len(payload))
+	offset, err := header.MarshalTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != header.MarshalSize() {
+		t.Fatalf("Expected payload offset %d, got %d", header.MarshalSize(), offset)
+	}
+	copy(buf[offset:], payload)
+
+	expected, err := (&Packet{Header: header, Payload: payload}).Marshal()
+	if err != nil {
+		t.Fatal(err)
 	}
-	header.Extensions[0].payload[0] = 0x1F
-	if clone.Extensions[0].payload[0] == 0x1F {
-		t.Errorf("Expected Extensions to be unchanged")
+	if !bytes.Equal(buf, expected) {
+		t.Fatalf("Expected %v, got %v", expected, buf)
+	}
+
+	if _, err = header.MarshalTo(buf[:offset-1]); !errors.Is(err, io.ErrShortBuffer) {
+		t.Fatalf("Expected io.ErrShortBuffer, got %v", err)
 	}
 }
 
-func TestClonePacket(t *testing.T) {
+func TestRFC8285TwoByteExtensionTooLarge(t *testing.T) {
+	header := Header{
+		Version:          2,
+		Extension:        true,
+		ExtensionProfile: extensionProfileTwoByte,
+		Extensions:       []Extension{{id: 1, payload: make([]byte, 256)}},
+	}
+
+	if _, err := header.Marshal(); !errors.Is(err, errRFC8285TwoByteHeaderSize) {
+		t.Fatalf("Expected %v, got %v", errRFC8285TwoByteHeaderSize, err)
+	}
+	if _, err := (&Packet{Header: header}).Marshal(); !errors.Is(err, errRFC8285TwoByteHeaderSize) {
+		t.Fatalf("Expected %v, got %v", errRFC8285TwoByteHeaderSize, err)
+	}
+
+	header.Extensions[0].payload = make([]byte, 255)
+	if _, err := header.Marshal(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRFC8285OneByteExtensionEmpty(t *testing.T) {
+	header := Header{Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte}
+	if err := header.SetExtension(1, []byte{}); !errors.Is(err, errRFC8285OneByteHeaderSize) {
+		t.Fatalf("Expected %v, got %v", errRFC8285OneByteHeaderSize, err)
+	}
+
+	// An empty payload set with the two-byte profile can't be marshaled with the one-byte profile
+	header.ExtensionProfile = extensionProfileTwoByte
+	if err := header.SetExtension(1, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	header.ExtensionProfile = extensionProfileOneByte
+	if _, err := header.Marshal(); !errors.Is(err, errRFC8285OneByteHeaderSize) {
+		t.Fatalf("Expected %v, got %v", errRFC8285OneByteHeaderSize, err)
+	}
+
+	header.Extensions[0].payload = make([]byte, 17)
+	if _, err := header.Marshal(); !errors.Is(err, errRFC8285OneByteHeaderSize) {
+		t.Fatalf("Expected %v, got %v", errRFC8285OneByteHeaderSize, err)
+	}
+}
+
+func TestHasExtension(t *testing.T) {
+	header := &Header{}
+	if header.HasExtension(1) {
+		t.Fatal("Expected no extension when extensions are disabled")
+	}
+
+	if err := header.SetExtension(1, []byte{0xAA}); err != nil {
+		t.Fatal(err)
+	}
+	if err := header.SetExtension(3, []byte{0xBB}); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, expected := range map[uint8]bool{1: true, 2: false, 3: true} {
+		if header.HasExtension(id) != expected {
+			t.Errorf("HasExtension(%d) should be %v", id, expected)
+		}
+	}
+
+	header.Extension = false
+	if header.HasExtension(1) {
+		t.Fatal("Expected no extension when extensions are disabled")
+	}
+}
+
+func TestHeaderValidate(t *testing.T) {
+	for name, test := range map[string]struct {
+		header Header
+		err    error
+	}{
+		"NoExtension": {
+			header: Header{Version: 2, CSRC: []uint32{1, 2}},
+		},
+		"OneByte": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte,
+				Extensions: []Extension{{id: 1, payload: []byte{0xAA}}},
+			},
+		},
+		"TwoByteEmpty": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileTwoByte,
+				Extensions: []Extension{{id: 1, payload: []byte{}}},
+			},
+		},
+		"OneByteNoExtensions": {
+			header: Header{Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte},
+		},
+		"RFC3550": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: []byte{0xAA, 0xBB, 0xCC, 0xDD}}},
+			},
+		},
+		// https://github.com/pion/rtp/issues/315
+		"Issue315": {
+			header: Header{Version: 2, Extension: true},
+			err:    errRFC3550HeaderCount,
+		},
+		"RFC3550TwoExtensions": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: make([]byte, 4)}, {id: 0, payload: make([]byte, 4)}},
+			},
+			err: errRFC3550HeaderCount,
+		},
+		"RFC3550Unaligned": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: make([]byte, 3)}},
+			},
+			err: errRFC3550HeaderSize,
+		},
+		"RFC3550ID": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 1, payload: make([]byte, 4)}},
+			},
+			err: errRFC3550HeaderIDRange,
+		},
+		"OneByteID": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte,
+				Extensions: []Extension{{id: 15, payload: []byte{0xAA}}},
+			},
+			err: errRFC8285OneByteHeaderIDRange,
+		},
+		"OneByteEmptyPayload": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte,
+				Extensions: []Extension{{id: 1, payload: []byte{}}},
+			},
+			err: errRFC8285OneByteHeaderSize,
+		},
+		"TwoByteID": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileTwoByte,
+				Extensions: []Extension{{id: 0, payload: []byte{0xAA}}},
+			},
+			err: errRFC8285TwoByteHeaderIDRange,
+		},
+		"TwoBytePayload": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileTwoByte,
+				Extensions: []Extension{{id: 1, payload: make([]byte, 256)}},
+			},
+			err: errRFC8285TwoByteHeaderSize,
+		},
+		"TooManyCSRC": {
+			header: Header{Version: 2, CSRC: make([]uint32, 16)},
+			err:    errTooManyCSRC,
+		},
+		"ExtensionTooLong": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: make([]byte, 4*65536)}},
+			},
+			err: errExtensionTooLong,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := test.header.Validate()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			raw, err := test.header.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) != test.header.MarshalSize() {
+				t.Fatalf("Marshaled %d bytes, MarshalSize returned %d", len(raw), test.header.MarshalSize())
+			}
+		})
+	}
+}
+
+func TestHeaderCacheKey(t *testing.T) {
+	headers := []Header{
+		{SSRC: 0, SequenceNumber: 0},
+		{SSRC: 0, SequenceNumber: 1},
+		{SSRC: 1, SequenceNumber: 0},
+		{SSRC: 1, SequenceNumber: 1},
+		{SSRC: 0, SequenceNumber: 0xFFFF},
+		{SSRC: 0xFFFFFFFF, SequenceNumber: 0},
+		{SSRC: 0xFFFFFFFF, SequenceNumber: 0xFFFF},
+	}
+
+	keys := map[uint64]Header{}
+	for _, header := range headers {
+		key := header.CacheKey()
+		if other, ok := keys[key]; ok {
+			t.Fatalf("Headers %v and %v have the same key %d", header, other, key)
+		}
+		keys[key] = header
+
+		// Fields other than the SSRC and sequence number don't change the key
+		other := header
+		other.Timestamp = 1234
+		other.PayloadType = 96
+		other.Marker = true
+		if other.CacheKey() != key || header.CacheKey() != key {
+			t.Fatalf("Unstable key for %v", header)
+		}
+	}
+}
+
+func TestHeaderPayloadBudget(t *testing.T) {
+	header := Header{Version: 2}
+	if budget := header.PayloadBudget(1200); budget != 1188 {
+		t.Fatalf("Expected budget 1188, got %d", budget)
+	}
+
+	header.CSRC = []uint32{1, 2}
+	if budget := header.PayloadBudget(1200); budget != 1180 {
+		t.Fatalf("Expected budget 1180 with CSRCs, got %d", budget)
+	}
+
+	// 4 bytes extension header and 4 bytes with a 2 bytes extension and padding
+	if err := header.SetExtension(1, []byte{0xAA, 0xBB}); err != nil {
+		t.Fatal(err)
+	}
+	if budget := header.PayloadBudget(1200); budget != 1172 {
+		t.Fatalf("Expected budget 1172 with an extension, got %d", budget)
+	}
+
+	payload := make([]byte, header.PayloadBudget(1200))
+	raw, err := (&Packet{Header: header, Payload: payload}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 1200 {
+		t.Fatalf("Expected a packet of 1200 bytes, got %d", len(raw))
+	}
+
+	for _, mtu := range []int{28, 10, 0, -1} {
+		if budget := header.PayloadBudget(mtu); budget != 0 {
+			t.Errorf("Expected budget 0 for MTU %d, got %d", mtu, budget)
+		}
+	}
+}
+
+func TestHeaderExtensionInfo(t *testing.T) {
+	for name, test := range map[string]struct {
+		header      Header
+		profile     uint16
+		numElements int
+		rawBlock    []byte
+	}{
+		"Disabled": {
+			header: Header{Version: 2},
+		},
+		"OneByte": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileOneByte, CSRC: []uint32{1},
+				Extensions: []Extension{{id: 1, payload: []byte{0xAA}}, {id: 2, payload: []byte{0xBB, 0xCC}}},
+			},
+			profile:     extensionProfileOneByte,
+			numElements: 2,
+			rawBlock:    []byte{0x10, 0xAA, 0x21, 0xBB, 0xCC, 0x00, 0x00, 0x00},
+		},
+		"TwoByte": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: extensionProfileTwoByte,
+				Extensions: []Extension{{id: 1, payload: []byte{}}, {id: 20, payload: []byte{0xAA}}},
+			},
+			profile:     extensionProfileTwoByte,
+			numElements: 2,
+			rawBlock:    []byte{0x01, 0x00, 0x14, 0x01, 0xAA, 0x00, 0x00, 0x00},
+		},
+		"RFC3550": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: []byte{0xAA, 0xBB, 0xCC, 0xDD}}},
+			},
+			profile:     0x1111,
+			numElements: 1,
+			rawBlock:    []byte{0xAA, 0xBB, 0xCC, 0xDD},
+		},
+		"Unmarshalable": {
+			header: Header{
+				Version: 2, Extension: true, ExtensionProfile: 0x1111,
+				Extensions: []Extension{{id: 0, payload: []byte{0xAA}}},
+			},
+			profile:     0x1111,
+			numElements: 1,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			profile, numElements, rawBlock := test.header.ExtensionInfo()
+			if profile != test.profile || numElements != test.numElements {
+				t.Fatalf("Expected profile %#x with %d elements, got %#x with %d",
+					test.profile, test.numElements, profile, numElements)
+			}
+			if !bytes.Equal(rawBlock, test.rawBlock) || (rawBlock == nil) != (test.rawBlock == nil) {
+				t.Fatalf("Expected raw block %v, got %v", test.rawBlock, rawBlock)
+			}
+		})
+	}
+
+	// An unmarshaled header returns the received extension, without marshaling it
+	rawPkt := []byte{
+		0x90, 0x60, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64, 0x27, 0x82,
+		0xBE, 0xDE, 0x00, 0x01, 0x10, 0xAA, 0xF0, 0x55, // ID 15 stops the parsing
+		0x98, 0x36,
+	}
+	header := &Header{}
+	if _, err := header.Unmarshal(rawPkt); err != nil {
+		t.Fatal(err)
+	}
+	if _, numElements, rawBlock := header.ExtensionInfo(); numElements != 1 || &rawBlock[0] != &rawPkt[16] ||
+		!bytes.Equal(rawBlock, rawPkt[16:20]) {
+		t.Fatalf("Expected the received raw block with 1 element, got %v with %d", rawBlock, numElements)
+	}
+
+	// Once the extensions change, the raw block is marshaled again
+	if err := header.SetExtension(2, []byte{0xBB}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, rawBlock := header.ExtensionInfo(); !bytes.Equal(rawBlock, []byte{0x10, 0xAA, 0x20, 0xBB}) {
+		t.Fatalf("Expected the marshaled raw block, got %v", rawBlock)
+	}
+}
+
+func TestPacketWriteTo(t *testing.T) {
+	packet := &Packet{
+		Header: Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 27023,
+			Timestamp:      3653407706,
+			SSRC:           476325762,
+		},
+	}
+	var _ io.WriterTo = packet
+
+	// The second packet reuses the buffer of the first one, with padding where the payload was
+	for _, paddingSize := range []byte{0, 4} {
+		packet.Payload = bytes.Repeat([]byte{0xFF}, 12-int(paddingSize))
+		packet.Padding = paddingSize != 0
+		packet.PaddingSize = paddingSize
+
+		expected, err := packet.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		n, err := packet.WriteTo(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(expected)) || !bytes.Equal(out.Bytes(), expected) {
+			t.Errorf("Padding %d: expected %v, got %v (%d bytes)", paddingSize, expected, out.Bytes(), n)
+		}
+	}
+
+	packet.PaddingSize = 0
+	if _, err := packet.WriteTo(&bytes.Buffer{}); !errors.Is(err, errInvalidRTPPadding) {
+		t.Errorf("Expected %v, got %v", errInvalidRTPPadding, err)
+	}
+}
+
+func TestUnmarshalCopy(t *testing.T) {
 	rawPkt := []byte{
 		0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64,
 		0x27, 0x82, 0xBE, 0xDE, 0x00, 0x01, 0x50, 0xAA, 0x00, 0x00,
 		0x98, 0x36, 0xbe, 0x88, 0x9e,
 	}
-	packet := &Packet{
-		Payload: rawPkt[20:],
+	buf := append([]byte{}, rawPkt...)
+
+	packet := &Packet{}
+	if err := packet.UnmarshalCopy(buf); err != nil {
+		t.Fatal(err)
 	}
 
-	clone := packet.Clone()
-	if !reflect.DeepEqual(packet, clone) {
-		t.Errorf("Cloned Packet does not match the original")
+	for i := range buf {
+		buf[i] = 0xFF
 	}
 
-	packet.Payload[0] = 0x1F
-	if clone.Payload[0] == 0x1F {
-		t.Errorf("Expected Payload to be unchanged")
+	if !bytes.Equal(packet.Payload, rawPkt[20:]) {
+		t.Errorf("Payload changed with the original buffer: %v", packet.Payload)
+	}
+	if ext := packet.GetExtension(5); !bytes.Equal(ext, []byte{0xAA}) {
+		t.Errorf("Extension payload changed with the original buffer: %v", ext)
+	}
+
+	// The packet storage is reused when large enough.
+	storage := &packet.buf[0]
+	if err := packet.UnmarshalCopy(rawPkt[:22]); err != nil {
+		t.Fatal(err)
+	}
+	if &packet.buf[0] != storage {
+		t.Error("Packet storage was not reused")
+	}
+	if !bytes.Equal(packet.Payload, rawPkt[20:22]) {
+		t.Errorf("Unexpected payload %v", packet.Payload)
+	}
+
+	if err := packet.UnmarshalCopy(rawPkt[:2]); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
+
+func TestParseRoutingInfo(t *testing.T) {
+	rawPkt := []byte{
+		0x90, 0xe0, 0x69, 0x8f, 0xd9, 0xc2, 0x93, 0xda, 0x1c, 0x64,
+		0x27, 0x82, 0x00, 0x01, 0x00, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0x98, 0x36, 0xbe, 0x88, 0x9e,
+	}
+
+	pt, marker, seq, ts, ssrc, err := ParseRoutingInfo(rawPkt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt != 96 || !marker || seq != 27023 || ts != 3653407706 || ssrc != 476325762 {
+		t.Errorf("Unexpected routing info: pt=%d marker=%v seq=%d ts=%d ssrc=%d", pt, marker, seq, ts, ssrc)
+	}
+
+	// Only the fixed header is required, the truncated extension is not inspected.
+	if _, _, _, _, _, err = ParseRoutingInfo(rawPkt[:12]); err != nil {
+		t.Errorf("Unexpected error for fixed-size header: %v", err)
+	}
+
+	if _, _, _, _, _, err = ParseRoutingInfo(rawPkt[:11]); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
+
+func TestPayloadType(t *testing.T) {
+	for _, expected := range []uint8{0, 8, 96, 97, 111, 127} {
+		for _, marker := range []byte{0x00, 0x80} {
+			pt, err := PayloadType([]byte{0x80, marker | expected})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pt != expected {
+				t.Errorf("Expected payload type %d with marker %#x, got %d", expected, marker, pt)
+			}
+		}
+	}
+
+	if _, err := PayloadType([]byte{0x80}); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
+
+func TestPacketString(t *testing.T) {
+	pkt := Packet{
+		Header: Header{
+			Version:          2,
+			Marker:           true,
+			PayloadType:      96,
+			SequenceNumber:   27023,
+			Timestamp:        3653407706,
+			SSRC:             476325762,
+			CSRC:             []uint32{1, 2},
+			Extension:        true,
+			ExtensionProfile: extensionProfileOneByte,
+			Extensions: []Extension{
+				{id: 1, payload: []byte{0xAA, 0xBB}},
+				{id: 3, payload: []byte{0x01}},
+			},
+		},
+		Payload: []byte{0x00, 0x01, 0x02},
+	}
+
+	expected := "RTP PACKET:\n" +
+		"\tVersion: 2\n" +
+		"\tMarker: true\n" +
+		"\tPayload Type: 96\n" +
+		"\tSequence Number: 27023\n" +
+		"\tTimestamp: 3653407706\n" +
+		"\tSSRC: 476325762 (1c642782)\n" +
+		"\tCSRC: [1 2]\n" +
+		"\tExtension Profile: 0xbede\n" +
+		"\tExtension ID 1: aabb\n" +
+		"\tExtension ID 3: 01\n" +
+		"\tPayload Length: 3\n"
+	if got := pkt.String(); got != expected {
+		t.Errorf("Packet.String() mismatch\nexpected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if got := pkt.Header.String(); !strings.Contains(got, "\tExtension ID 1: aabb\n") {
+		t.Errorf("Header.String() should contain extensions, got:\n%s", got)
+	}
+
+	// Packets without CSRC and extensions keep the original format
+	pkt.CSRC = nil
+	pkt.Extension = false
+	pkt.Extensions = nil
+	expected = "RTP PACKET:\n" +
+		"\tVersion: 2\n" +
+		"\tMarker: true\n" +
+		"\tPayload Type: 96\n" +
+		"\tSequence Number: 27023\n" +
+		"\tTimestamp: 3653407706\n" +
+		"\tSSRC: 476325762 (1c642782)\n" +
+		"\tPayload Length: 3\n"
+	if got := pkt.String(); got != expected {
+		t.Errorf("Packet.String() mismatch\nexpected:\n%s\ngot:\n%s", expected, got)
 	}
 }
 
@@ -1552,3 +3210,45 @@ func BenchmarkUnmarshal(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkParseRoutingInfo(b *testing.B) {
+	pkt := Packet{
+		Header: Header{
+			Extension:        true,
+			CSRC:             []uint32{1, 2},
+			ExtensionProfile: extensionProfileTwoByte,
+			Extensions: []Extension{
+				{id: 1, payload: []byte{3, 4}},
+				{id: 2, payload: []byte{5, 6}},
+			},
+		},
+		Payload: []byte{
+			0x07, 0x08, 0x09, 0x0a,
+		},
+	}
+	rawPkt, errMarshal := pkt.Marshal()
+	if errMarshal != nil {
+		b.Fatal(errMarshal)
+	}
+
+	b.Run("ParseRoutingInfo", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, _, _, _, err := ParseRoutingInfo(rawPkt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Unmarshal", func(b *testing.B) {
+		packet := &Packet{}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := packet.Unmarshal(rawPkt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
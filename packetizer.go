@@ -12,12 +12,24 @@ type Payloader interface {
 	Payload(mtu uint16, payload []byte) [][]byte
 }
 
+// MarkerFunc decides whether the packet at index of the payloads produced for
+// a single Packetize call should have the marker bit set.
+type MarkerFunc func(payloads [][]byte, index int) bool
+
+// PaddingFillFunc returns the content of the n padding bytes preceding the
+// padding count byte of a padding packet.
+type PaddingFillFunc func(n int) []byte
+
 // Packetizer packetizes a payload.
 type Packetizer interface {
 	Packetize(payload []byte, samples uint32) []*Packet
+	PacketizeFrames(frames [][]byte, samplesPerFrame uint32) [][]*Packet
 	GeneratePadding(samples uint32) []*Packet
 	EnableAbsSendTime(value int)
+	EnableMarker(markerFn MarkerFunc)
 	SkipSamples(skippedSamples uint32)
+	SetPayloader(payloader Payloader)
+	SetPaddingFill(fill PaddingFillFunc)
 }
 
 type packetizer struct {
@@ -35,7 +47,9 @@ type packetizer struct {
 	extensionNumbers struct {
 		AbsSendTime int // http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time
 	}
-	timegen func() time.Time
+	timegen     func() time.Time
+	markerFn    MarkerFunc
+	paddingFill PaddingFillFunc
 }
 
 // NewPacketizer returns a new instance of a Packetizer for a specific payloader.
@@ -47,7 +61,46 @@ func NewPacketizer(
 	sequencer Sequencer,
 	clockRate uint32,
 ) Packetizer {
-	return &packetizer{
+	return NewPacketizerWithOptions(mtu, pt, ssrc, payloader, sequencer, clockRate)
+}
+
+// PacketizerOption configures a Packetizer created by NewPacketizerWithOptions.
+type PacketizerOption func(*packetizer)
+
+// WithStartTimestamp sets the timestamp of the first packet instead of a random one.
+func WithStartTimestamp(timestamp uint32) PacketizerOption {
+	return func(p *packetizer) {
+		p.Timestamp = timestamp
+	}
+}
+
+// WithStartSequence makes the packetizer number packets from seq, replacing
+// the sequencer given to NewPacketizerWithOptions with a fixed one.
+func WithStartSequence(seq uint16) PacketizerOption {
+	return func(p *packetizer) {
+		p.Sequencer = NewFixedSequencer(seq)
+	}
+}
+
+// WithClock sets the clock used for the abs-send-time extension instead of time.Now.
+func WithClock(now func() time.Time) PacketizerOption {
+	return func(p *packetizer) {
+		p.timegen = now
+	}
+}
+
+// NewPacketizerWithOptions returns a new instance of a Packetizer like
+// NewPacketizer, configured by the given options.
+func NewPacketizerWithOptions(
+	mtu uint16,
+	pt uint8,
+	ssrc uint32,
+	payloader Payloader,
+	sequencer Sequencer,
+	clockRate uint32,
+	opts ...PacketizerOption,
+) Packetizer {
+	p := &packetizer{
 		MTU:         mtu,
 		PayloadType: pt,
 		SSRC:        ssrc,
@@ -57,12 +110,31 @@ func NewPacketizer(
 		ClockRate:   clockRate,
 		timegen:     time.Now,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *packetizer) EnableAbsSendTime(value int) {
 	p.extensionNumbers.AbsSendTime = value
 }
 
+// EnableMarker sets the function deciding which packets get the marker bit.
+// By default, and when markerFn is nil, the last packet of each Packetize call is marked.
+func (p *packetizer) EnableMarker(markerFn MarkerFunc) {
+	p.markerFn = markerFn
+}
+
+func (p *packetizer) isMarker(payloads [][]byte, index int) bool {
+	if p.markerFn != nil {
+		return p.markerFn(payloads, index)
+	}
+
+	return index == len(payloads)-1
+}
+
 // Packetize packetizes the payload of an RTP packet and returns one or more RTP packets.
 func (p *packetizer) Packetize(payload []byte, samples uint32) []*Packet {
 	// Guard against an empty payload
@@ -79,7 +151,7 @@ func (p *packetizer) Packetize(payload []byte, samples uint32) []*Packet {
 				Version:        2,
 				Padding:        false,
 				Extension:      false,
-				Marker:         i == len(payloads)-1,
+				Marker:         p.isMarker(payloads, i),
 				PayloadType:    p.PayloadType,
 				SequenceNumber: p.Sequencer.NextSequenceNumber(),
 				Timestamp:      p.Timestamp, // Figure out how to do timestamps
@@ -107,6 +179,22 @@ func (p *packetizer) Packetize(payload []byte, samples uint32) []*Packet {
 	return packets
 }
 
+// PacketizeFrames packetizes each frame like Packetize and returns the packets
+// grouped per frame. The marker bit is set as by Packetize for each frame: on
+// its last packet by default, or as decided by the function set with EnableMarker.
+func (p *packetizer) PacketizeFrames(frames [][]byte, samplesPerFrame uint32) [][]*Packet {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	groups := make([][]*Packet, len(frames))
+	for i, frame := range frames {
+		groups[i] = p.Packetize(frame, samplesPerFrame)
+	}
+
+	return groups
+}
+
 // GeneratePadding returns required padding-only packages.
 func (p *packetizer) GeneratePadding(samples uint32) []*Packet {
 	// Guard against an empty payload
@@ -118,6 +206,9 @@ func (p *packetizer) GeneratePadding(samples uint32) []*Packet {
 
 	for i := 0; i < int(samples); i++ {
 		pp := make([]byte, 255)
+		if p.paddingFill != nil {
+			copy(pp[:254], p.paddingFill(254))
+		}
 		pp[254] = 255
 
 		packets[i] = &Packet{
@@ -144,3 +235,16 @@ func (p *packetizer) GeneratePadding(samples uint32) []*Packet {
 func (p *packetizer) SkipSamples(skippedSamples uint32) {
 	p.Timestamp += skippedSamples
 }
+
+// SetPayloader replaces the payloader used for subsequent Packetize calls.
+// Sequence numbers and timestamps continue from the current state.
+func (p *packetizer) SetPayloader(payloader Payloader) {
+	p.Payloader = payloader
+}
+
+// SetPaddingFill sets the function providing the content of padding packets,
+// such as random bytes as suggested by RFC 3711. The last byte of each padding
+// packet is always the padding count. By default, and when fill is nil, padding is zeroed.
+func (p *packetizer) SetPaddingFill(fill PaddingFillFunc) {
+	p.paddingFill = fill
+}
@@ -75,6 +75,35 @@ func TestPacketizer_AbsSendTime(t *testing.T) {
 	}
 }
 
+func TestNewPacketizerWithOptions(t *testing.T) {
+	sendTime := time.Date(1985, time.June, 23, 4, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	pktizer := NewPacketizerWithOptions(
+		100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewRandomSequencer(), 8000,
+		WithStartTimestamp(45678),
+		WithStartSequence(65535),
+		WithClock(func() time.Time { return sendTime }),
+	)
+	pktizer.EnableAbsSendTime(1)
+
+	packets := pktizer.Packetize([]byte{0x11, 0x12}, 160)
+	packets = append(packets, pktizer.Packetize([]byte{0x13, 0x14}, 160)...)
+	if len(packets) != 2 {
+		t.Fatalf("Generated %d packets instead of 2", len(packets))
+	}
+
+	if packets[0].SequenceNumber != 65535 || packets[0].Timestamp != 45678 {
+		t.Errorf("Expected first packet seq 65535 and ts 45678, got %d and %d",
+			packets[0].SequenceNumber, packets[0].Timestamp)
+	}
+	if packets[1].SequenceNumber != 0 || packets[1].Timestamp != 45838 {
+		t.Errorf("Expected second packet seq 0 and ts 45838, got %d and %d",
+			packets[1].SequenceNumber, packets[1].Timestamp)
+	}
+	if ext := packets[0].GetExtension(1); !reflect.DeepEqual(ext, []byte{0x40, 0, 0}) {
+		t.Errorf("Expected abs-send-time from the provided clock, got %v", ext)
+	}
+}
+
 func TestPacketizer_Roundtrip(t *testing.T) { //nolint:cyclop
 	multiplepayload := make([]byte, 128)
 	packetizer := NewPacketizer(100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewRandomSequencer(), 90000)
@@ -150,3 +179,201 @@ func TestPacketizer_Roundtrip(t *testing.T) { //nolint:cyclop
 		}
 	}
 }
+
+func TestPacketizer_SetPayloader(t *testing.T) {
+	pktizer := NewPacketizer(100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewFixedSequencer(65534), 90000)
+
+	packets := pktizer.Packetize(make([]byte, 128), 2000)
+	if len(packets) != 2 {
+		t.Fatalf("Generated %d packets instead of 2", len(packets))
+	}
+
+	pktizer.SetPayloader(&codecs.OpusPayloader{})
+	packets = append(packets, pktizer.Packetize(make([]byte, 128), 2000)...)
+	if len(packets) != 3 {
+		t.Fatalf("Generated %d packets instead of 3", len(packets))
+	}
+	if len(packets[2].Payload) != 128 {
+		t.Errorf("New payloader was not used, payload length %d", len(packets[2].Payload))
+	}
+
+	expectedSequenceNumbers := []uint16{65534, 65535, 0}
+	for i, pkt := range packets {
+		if pkt.SequenceNumber != expectedSequenceNumbers[i] {
+			t.Errorf("Packet %d: expected sequence number %d, got %d", i, expectedSequenceNumbers[i], pkt.SequenceNumber)
+		}
+	}
+	if packets[2].Timestamp != packets[0].Timestamp+2000 {
+		t.Errorf("Timestamp is not continuous: %d -> %d", packets[0].Timestamp, packets[2].Timestamp)
+	}
+}
+
+func TestPacketizer_EnableMarker(t *testing.T) {
+	pktizer := NewPacketizer(100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewRandomSequencer(), 90000)
+
+	// Default behavior marks the last packet
+	packets := pktizer.Packetize(make([]byte, 200), 2000)
+	for i, pkt := range packets {
+		if pkt.Marker != (i == len(packets)-1) {
+			t.Errorf("Packet %d: unexpected marker %v with default strategy", i, pkt.Marker)
+		}
+	}
+
+	// Custom strategy marks only the first packet of the first call
+	first := true
+	pktizer.EnableMarker(func(_ [][]byte, index int) bool {
+		marker := first && index == 0
+		if index == 0 {
+			first = false
+		}
+
+		return marker
+	})
+
+	for call := 0; call < 2; call++ {
+		packets = pktizer.Packetize(make([]byte, 200), 2000)
+		if len(packets) != 3 {
+			t.Fatalf("Generated %d packets instead of 3", len(packets))
+		}
+		for i, pkt := range packets {
+			if expected := call == 0 && i == 0; pkt.Marker != expected {
+				t.Errorf("Call %d packet %d: expected marker %v, got %v", call, i, expected, pkt.Marker)
+			}
+		}
+	}
+
+	// A nil strategy restores the default behavior
+	pktizer.EnableMarker(nil)
+	packets = pktizer.Packetize(make([]byte, 200), 2000)
+	if !packets[len(packets)-1].Marker || packets[0].Marker {
+		t.Error("Default marker behavior not restored")
+	}
+}
+
+func TestPacketizer_OpusTalkspurtMarker(t *testing.T) {
+	payloader := &codecs.OpusPayloader{}
+	pktizer := NewPacketizer(100, 111, 0x1234ABCD, payloader, NewRandomSequencer(), 48000)
+	pktizer.EnableMarker(payloader.Marker)
+
+	for i, startOfTalkspurt := range []bool{true, false, false, true, false} {
+		payloader.StartOfTalkspurt = startOfTalkspurt
+		packets := pktizer.Packetize([]byte{0xFC, 0xFF, 0xFE}, 960)
+		if len(packets) != 1 {
+			t.Fatalf("Generated %d packets instead of 1", len(packets))
+		}
+		if packets[0].Marker != startOfTalkspurt {
+			t.Errorf("Packet %d: expected marker %v, got %v", i, startOfTalkspurt, packets[0].Marker)
+		}
+	}
+}
+
+func TestPacketizer_SetPaddingFill(t *testing.T) {
+	pktizer := NewPacketizer(100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewRandomSequencer(), 90000)
+
+	// Default fill is zeros
+	packets := pktizer.GeneratePadding(1)
+	expected := make([]byte, 255)
+	expected[254] = 255
+	if !reflect.DeepEqual(packets[0].Payload, expected) {
+		t.Errorf("Expected zeroed padding, got %v", packets[0].Payload)
+	}
+
+	requested := 0
+	pktizer.SetPaddingFill(func(n int) []byte {
+		requested = n
+		fill := make([]byte, n)
+		for i := range fill {
+			fill[i] = 0xAB
+		}
+
+		return fill
+	})
+
+	packets = pktizer.GeneratePadding(2)
+	if requested != 254 {
+		t.Errorf("Expected fill of 254 bytes to be requested, got %d", requested)
+	}
+	for i := range expected[:254] {
+		expected[i] = 0xAB
+	}
+	for i, pkt := range packets {
+		if !pkt.Padding {
+			t.Errorf("Packet %d: expected padding bit to be set", i)
+		}
+		if !reflect.DeepEqual(pkt.Payload, expected) {
+			t.Errorf("Packet %d: expected filled padding, got %v", i, pkt.Payload)
+		}
+	}
+
+	// A short fill leaves the remaining bytes zeroed and the count intact
+	pktizer.SetPaddingFill(func(int) []byte { return []byte{0x01, 0x02} })
+	packets = pktizer.GeneratePadding(1)
+	if payload := packets[0].Payload; payload[0] != 0x01 || payload[1] != 0x02 || payload[2] != 0 || payload[254] != 255 {
+		t.Errorf("Unexpected padding with short fill: %v", payload)
+	}
+}
+
+func TestPacketizer_PacketizeFrames(t *testing.T) {
+	pktizer := NewPacketizer(100, 98, 0x1234ABCD, &codecs.G722Payloader{}, NewFixedSequencer(65534), 90000)
+
+	groups := pktizer.PacketizeFrames([][]byte{make([]byte, 200), make([]byte, 50), make([]byte, 128)}, 3000)
+	expectedLengths := []int{3, 1, 2}
+	if len(groups) != len(expectedLengths) {
+		t.Fatalf("Generated %d frames instead of %d", len(groups), len(expectedLengths))
+	}
+
+	expectedSequenceNumber := uint16(65534)
+	firstTimestamp := groups[0][0].Timestamp
+	for i, packets := range groups {
+		if len(packets) != expectedLengths[i] {
+			t.Fatalf("Frame %d: generated %d packets instead of %d", i, len(packets), expectedLengths[i])
+		}
+		for j, pkt := range packets {
+			if pkt.Marker != (j == len(packets)-1) {
+				t.Errorf("Frame %d packet %d: unexpected marker %v", i, j, pkt.Marker)
+			}
+			if expected := firstTimestamp + uint32(i)*3000; pkt.Timestamp != expected {
+				t.Errorf("Frame %d packet %d: expected timestamp %d, got %d", i, j, expected, pkt.Timestamp)
+			}
+			if pkt.SequenceNumber != expectedSequenceNumber {
+				t.Errorf("Frame %d packet %d: expected sequence number %d, got %d",
+					i, j, expectedSequenceNumber, pkt.SequenceNumber)
+			}
+			expectedSequenceNumber++
+		}
+	}
+
+	if groups = pktizer.PacketizeFrames(nil, 3000); groups != nil {
+		t.Errorf("Expected no frames, got %v", groups)
+	}
+}
+
+func TestPacketizer_AudioPayloaders(t *testing.T) {
+	for name, payloader := range map[string]Payloader{
+		"G711": &codecs.G711Payloader{},
+		"G722": &codecs.G722Payloader{},
+	} {
+		payloader := payloader
+		t.Run(name, func(t *testing.T) {
+			// 40ms of audio at the 8000 Hz RTP clock rate, 160 bytes per packet
+			pktizer := NewPacketizer(12+160, 0, 0x1234ABCD, payloader, NewRandomSequencer(), 8000)
+			packets := pktizer.Packetize(make([]byte, 320), 320)
+			if len(packets) != 2 {
+				t.Fatalf("Generated %d packets instead of 2", len(packets))
+			}
+			for i, pkt := range packets {
+				if len(pkt.Payload) != 160 {
+					t.Errorf("Packet %d: expected 160 bytes of payload, got %d", i, len(pkt.Payload))
+				}
+				if size := pkt.MarshalSize(); size > 12+160 {
+					t.Errorf("Packet %d: size %d exceeds the MTU", i, size)
+				}
+			}
+
+			next := pktizer.Packetize(make([]byte, 100), 100)
+			if len(next) != 1 || next[0].Timestamp != packets[0].Timestamp+320 {
+				t.Errorf("Expected a single packet with the timestamp advanced by 320")
+			}
+		})
+	}
+}
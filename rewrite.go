@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// SetSequenceNumber overwrites the sequence number of a marshaled RTP packet in place.
+func SetSequenceNumber(buf []byte, seq uint16) error {
+	if len(buf) < csrcOffset {
+		return fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), csrcOffset)
+	}
+
+	binary.BigEndian.PutUint16(buf[seqNumOffset:seqNumOffset+seqNumLength], seq)
+
+	return nil
+}
+
+// SetTimestamp overwrites the timestamp of a marshaled RTP packet in place.
+func SetTimestamp(buf []byte, ts uint32) error {
+	if len(buf) < csrcOffset {
+		return fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), csrcOffset)
+	}
+
+	binary.BigEndian.PutUint32(buf[timestampOffset:timestampOffset+timestampLength], ts)
+
+	return nil
+}
+
+// SetSSRC overwrites the SSRC of a marshaled RTP packet in place.
+func SetSSRC(buf []byte, ssrc uint32) error {
+	if len(buf) < csrcOffset {
+		return fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(buf), csrcOffset)
+	}
+
+	binary.BigEndian.PutUint32(buf[ssrcOffset:ssrcOffset+ssrcLength], ssrc)
+
+	return nil
+}
+
+// RewriteStream adds seqOffset to the sequence number and tsOffset to the
+// timestamp of each marshaled RTP packet, modifying the buffers in place.
+// Both wrap around. No packet is modified if any of them is too short.
+func RewriteStream(packets [][]byte, seqOffset uint16, tsOffset uint32) error {
+	for i, buf := range packets {
+		if _, _, _, _, _, err := ParseRoutingInfo(buf); err != nil { // nolint: dogsled
+			return fmt.Errorf("packet %d: %w", i, err)
+		}
+	}
+
+	for _, buf := range packets {
+		_, _, seq, ts, _, err := ParseRoutingInfo(buf) // nolint: dogsled
+		if err != nil {
+			return err
+		}
+
+		if err := SetSequenceNumber(buf, seq+seqOffset); err != nil {
+			return err
+		}
+		if err := SetTimestamp(buf, ts+tsOffset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalWithHeaderTemplate writes a packet made of the marshaled header
+// headerBytes and payload to buf, and returns the number of bytes written.
+// The sequence number, SSRC and payload type of the template are replaced,
+// keeping the marker bit, so packets sharing a header, such as RTX
+// retransmissions, don't need the header to be marshaled again. The template
+// must not have the padding bit set, as no padding is added.
+func MarshalWithHeaderTemplate(
+	headerBytes []byte,
+	seq uint16,
+	ssrc uint32,
+	pt uint8,
+	payload []byte,
+	buf []byte,
+) (int, error) {
+	if len(headerBytes) < csrcOffset {
+		return 0, fmt.Errorf("%w: %d < %d", errHeaderSizeInsufficient, len(headerBytes), csrcOffset)
+	}
+
+	size := len(headerBytes) + len(payload)
+	if len(buf) < size {
+		return 0, io.ErrShortBuffer
+	}
+
+	n := copy(buf, headerBytes)
+	n += copy(buf[n:], payload)
+
+	buf[1] = (buf[1] &^ ptMask) | (pt & ptMask)
+	if err := SetSequenceNumber(buf, seq); err != nil {
+		return 0, err
+	}
+	if err := SetSSRC(buf, ssrc); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRewriteStream(t *testing.T) {
+	var packets [][]byte
+	for i, seq := range []uint16{65533, 65534, 65535, 0} {
+		pkt := Packet{
+			Header: Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: seq,
+				Timestamp:      0xFFFFFF00 + uint32(i)*0x40,
+				SSRC:           0x1234ABCD,
+			},
+			Payload: []byte{byte(i), 0x01, 0x02},
+		}
+		raw, err := pkt.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, raw)
+	}
+
+	if err := RewriteStream(packets, 10, 0x200); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSequenceNumbers := []uint16{7, 8, 9, 10}
+	expectedTimestamps := []uint32{0x100, 0x140, 0x180, 0x1C0}
+	for i, raw := range packets {
+		pkt := &Packet{}
+		if err := pkt.Unmarshal(raw); err != nil {
+			t.Fatal(err)
+		}
+		if pkt.SequenceNumber != expectedSequenceNumbers[i] {
+			t.Errorf("Packet %d: expected sequence number %d, got %d", i, expectedSequenceNumbers[i], pkt.SequenceNumber)
+		}
+		if pkt.Timestamp != expectedTimestamps[i] {
+			t.Errorf("Packet %d: expected timestamp %#x, got %#x", i, expectedTimestamps[i], pkt.Timestamp)
+		}
+		if pkt.SSRC != 0x1234ABCD || !bytes.Equal(pkt.Payload, []byte{byte(i), 0x01, 0x02}) {
+			t.Errorf("Packet %d: unexpected change to SSRC or payload", i)
+		}
+	}
+
+	// A short packet fails the whole batch without modifying it
+	original := append([]byte{}, packets[0]...)
+	err := RewriteStream([][]byte{packets[0], packets[1][:11]}, 1, 1)
+	if !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+	if !bytes.Equal(packets[0], original) {
+		t.Error("Packet was modified despite the error")
+	}
+}
+
+func TestSetSequenceNumberAndTimestamp(t *testing.T) {
+	raw := make([]byte, 12)
+	if err := SetSequenceNumber(raw, 0x1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTimestamp(raw, 0xDEADBEEF); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw[2:8], []byte{0x12, 0x34, 0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("Unexpected header %x", raw)
+	}
+
+	if err := SetSequenceNumber(raw[:11], 0); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+	if err := SetTimestamp(raw[:11], 0); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
+
+func TestSetSSRC(t *testing.T) {
+	raw := make([]byte, 12)
+	if err := SetSSRC(raw, 0xDEADBEEF); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw[8:12], []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("Unexpected header %x", raw)
+	}
+
+	if err := SetSSRC(raw[:11], 0); !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Errorf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
+
+func TestMarshalWithHeaderTemplate(t *testing.T) {
+	template := Header{
+		Version:          2,
+		Marker:           true,
+		PayloadType:      96,
+		SequenceNumber:   1,
+		Timestamp:        3653407706,
+		SSRC:             1,
+		CSRC:             []uint32{0xAABBCCDD},
+		Extension:        true,
+		ExtensionProfile: extensionProfileOneByte,
+		Extensions:       []Extension{{id: 1, payload: []byte{0x01, 0x02}}},
+	}
+	headerBytes, err := template.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	for _, seq := range []uint16{100, 101, 65535} {
+		payload := []byte{byte(seq >> 8), byte(seq), 0xAA, 0xBB}
+
+		n, err := MarshalWithHeaderTemplate(headerBytes, seq, 0x12345678, 97, payload, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := template.Clone()
+		header.SequenceNumber = seq
+		header.SSRC = 0x12345678
+		header.PayloadType = 97
+		expected, err := (&Packet{Header: header, Payload: payload}).Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf[:n], expected) {
+			t.Fatalf("Expected %v, got %v", expected, buf[:n])
+		}
+	}
+
+	_, err = MarshalWithHeaderTemplate(headerBytes, 1, 1, 97, []byte{0xAA}, buf[:len(headerBytes)])
+	if !errors.Is(err, io.ErrShortBuffer) {
+		t.Fatalf("Expected io.ErrShortBuffer, got %v", err)
+	}
+	_, err = MarshalWithHeaderTemplate(headerBytes[:11], 1, 1, 97, nil, buf)
+	if !errors.Is(err, errHeaderSizeInsufficient) {
+		t.Fatalf("Expected %v, got %v", errHeaderSizeInsufficient, err)
+	}
+}
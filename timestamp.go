@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"time"
+)
+
+// SetTimestampFromDuration sets the timestamp of the packet to d converted to
+// ticks of clockRate, rounded to the nearest tick. Durations longer than the
+// timestamp range wrap around, like RTP timestamps do.
+func (p *Packet) SetTimestampFromDuration(d time.Duration, clockRate uint32) {
+	// Split the duration in seconds to avoid overflowing int64 on long durations
+	seconds := int64(d / time.Second)
+	nanos := int64(d % time.Second)
+
+	rounding := int64(time.Second / 2)
+	if nanos < 0 {
+		rounding = -rounding
+	}
+	ticks := seconds*int64(clockRate) + (nanos*int64(clockRate)+rounding)/int64(time.Second)
+
+	p.Timestamp = uint32(ticks) // nolint: gosec // G115
+}
+
+// TimestampDuration returns the timestamp of the packet converted from ticks
+// of clockRate to a duration, rounded to the nearest nanosecond. It returns
+// zero if clockRate is zero.
+func (p *Packet) TimestampDuration(clockRate uint32) time.Duration {
+	if clockRate == 0 {
+		return 0
+	}
+
+	nanos := (uint64(p.Timestamp)*uint64(time.Second) + uint64(clockRate)/2) / uint64(clockRate)
+
+	return time.Duration(nanos) // nolint: gosec // G115
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package rtp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetTimestampFromDuration(t *testing.T) {
+	for name, test := range map[string]struct {
+		duration  time.Duration
+		clockRate uint32
+		expected  uint32
+	}{
+		"Zero":             {duration: 0, clockRate: 90000, expected: 0},
+		"90kHzOneSecond":   {duration: time.Second, clockRate: 90000, expected: 90000},
+		"90kHzFrame":       {duration: time.Second / 30, clockRate: 90000, expected: 3000},
+		"90kHzRoundDown":   {duration: 11111 * time.Nanosecond, clockRate: 90000, expected: 1},
+		"90kHzRoundUp":     {duration: 5556 * time.Nanosecond, clockRate: 90000, expected: 1},
+		"90kHzBelowHalf":   {duration: 5555 * time.Nanosecond, clockRate: 90000, expected: 0},
+		"48kHzOpusFrame":   {duration: 20 * time.Millisecond, clockRate: 48000, expected: 960},
+		"48kHzRoundUp":     {duration: 10417 * time.Nanosecond, clockRate: 48000, expected: 1},
+		"48kHzRoundDown":   {duration: 10416 * time.Nanosecond, clockRate: 48000, expected: 0},
+		"48kHzMixed":       {duration: 2*time.Second + 31250*time.Nanosecond, clockRate: 48000, expected: 96002},
+		"90kHzWraparound":  {duration: 47721858844444 * time.Nanosecond, clockRate: 90000, expected: 0},
+		"ZeroClockRate":    {duration: time.Second, clockRate: 0, expected: 0},
+		"NegativeDuration": {duration: -time.Second, clockRate: 90000, expected: 0xFFFFFFFF - 90000 + 1},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			packet := &Packet{}
+			packet.SetTimestampFromDuration(test.duration, test.clockRate)
+			if packet.Timestamp != test.expected {
+				t.Fatalf("Expected timestamp %d, got %d", test.expected, packet.Timestamp)
+			}
+		})
+	}
+}
+
+func TestTimestampDuration(t *testing.T) {
+	for name, test := range map[string]struct {
+		timestamp uint32
+		clockRate uint32
+		expected  time.Duration
+	}{
+		"Zero":           {timestamp: 0, clockRate: 90000, expected: 0},
+		"90kHzOneSecond": {timestamp: 90000, clockRate: 90000, expected: time.Second},
+		"90kHzFrame":     {timestamp: 3000, clockRate: 90000, expected: time.Second / 30},
+		"90kHzRoundUp":   {timestamp: 1, clockRate: 90000, expected: 11111 * time.Nanosecond},
+		"90kHzRoundDown": {timestamp: 2, clockRate: 90000, expected: 22222 * time.Nanosecond},
+		"48kHzOpusFrame": {timestamp: 960, clockRate: 48000, expected: 20 * time.Millisecond},
+		"48kHzRoundUp":   {timestamp: 1, clockRate: 48000, expected: 20833 * time.Nanosecond},
+		"48kHzRoundDown": {timestamp: 5, clockRate: 48000, expected: 104167 * time.Nanosecond},
+		"48kHzMaxTicks":  {timestamp: 0xFFFFFFFF, clockRate: 48000, expected: 89478485312500 * time.Nanosecond},
+		"ZeroClockRate":  {timestamp: 90000, clockRate: 0, expected: 0},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			packet := &Packet{Header: Header{Timestamp: test.timestamp}}
+			if d := packet.TimestampDuration(test.clockRate); d != test.expected {
+				t.Fatalf("Expected %v, got %v", test.expected, d)
+			}
+		})
+	}
+}
+
+func TestTimestampDurationRoundTrip(t *testing.T) {
+	for _, clockRate := range []uint32{90000, 48000} {
+		packet := &Packet{}
+		for ticks := uint32(0); ticks < 1000; ticks += 7 {
+			packet.Timestamp = ticks
+			packet.SetTimestampFromDuration(packet.TimestampDuration(clockRate), clockRate)
+			if packet.Timestamp != ticks {
+				t.Fatalf("Round trip of %d ticks at %d Hz gave %d", ticks, clockRate, packet.Timestamp)
+			}
+		}
+	}
+}